@@ -0,0 +1,77 @@
+// Package breach screening of passwords against corpus of breached passwords
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // HIBP corpus is keyed by SHA-1 of password
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Length of SHA-1 prefix naming range bucket
+const prefixLength = 5
+
+// Range corpus in HIBP range format, directory holds bucket file per SHA-1 prefix,
+// e.g. 21BD1 or 21BD1.txt, with lines SUFFIX:COUNT
+type Range struct {
+	dir string
+}
+
+// NewRange creating new Range corpus, directory must exist
+func NewRange(dir string) (*Range, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("range - NewRange - Stat: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("range - NewRange - %q is not a directory", dir)
+	}
+
+	return &Range{dir: dir}, nil
+}
+
+// IsBreached check if password is in corpus, missing bucket means password is not breached
+func (r *Range) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // HIBP corpus is keyed by SHA-1 of password
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	f, err := r.open(prefix)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("range - IsBreached - open: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineSuffix, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+		// padding entries of HIBP responses have zero count
+		n, err := strconv.Atoi(count)
+		return err != nil || n > 0, nil
+	}
+	if err = scanner.Err(); err != nil {
+		return false, fmt.Errorf("range - IsBreached - Scan: %w", err)
+	}
+
+	return false, nil
+}
+
+func (r *Range) open(prefix string) (*os.File, error) {
+	f, err := os.Open(filepath.Join(r.dir, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Open(filepath.Join(r.dir, prefix+".txt"))
+	}
+
+	return f, err
+}
@@ -3,20 +3,97 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v7"
 )
 
 // MainConfig with init data
 type MainConfig struct {
-	PostgresPort     string `env:"POSTGRES_PORT,notEmpty" envDefault:"5432"`
-	PostgresHost     string `env:"POSTGRES_HOST,notEmpty" envDefault:"localhost"`
-	PostgresPassword string `env:"POSTGRES_PASSWORD,notEmpty" envDefault:"postgres"`
-	PostgresUser     string `env:"POSTGRES_USER,notEmpty" envDefault:"postgres"`
-	PostgresDB       string `env:"POSTGRES_DB,notEmpty" envDefault:"postgres"`
-	JwtKey           string `env:"JWT_KEY,notEmpty" envDefault:"874967EC3EA3490F8F2EF6478B72A756"`
-	Port             string `env:"PORT,notEmpty" envDefault:"10000"`
-	Host             string `env:"HOST,notEmpty" envDefault:"localhost"`
+	PostgresPort      string        `env:"POSTGRES_PORT,notEmpty" envDefault:"5432"`
+	PostgresHost      string        `env:"POSTGRES_HOST,notEmpty" envDefault:"localhost"`
+	PostgresPassword  string        `env:"POSTGRES_PASSWORD,notEmpty" envDefault:"postgres"`
+	PostgresUser      string        `env:"POSTGRES_USER,notEmpty" envDefault:"postgres"`
+	PostgresDB        string        `env:"POSTGRES_DB,notEmpty" envDefault:"postgres"`
+	TokenFormat       string        `env:"TOKEN_FORMAT,notEmpty" envDefault:"jwt"`
+	JwtAlgorithm      string        `env:"JWT_ALGORITHM,notEmpty" envDefault:"EdDSA"`
+	JwtKeysDir        string        `env:"JWT_KEYS_DIR,notEmpty" envDefault:"keys"`
+	JwtKeyRotator     bool          `env:"JWT_KEY_ROTATOR" envDefault:"true"`
+	JwtKeysReload     time.Duration `env:"JWT_KEYS_RELOAD,notEmpty" envDefault:"1m"`
+	JwtRotationPeriod time.Duration `env:"JWT_ROTATION_PERIOD,notEmpty" envDefault:"720h"`
+	JwksFile          string        `env:"JWKS_FILE"`
+	JwtIssuer         string        `env:"JWT_ISSUER,notEmpty" envDefault:"user-service"`
+	JwtAudience       string        `env:"JWT_AUDIENCE,notEmpty" envDefault:"user-service"`
+	JwtAudiences      []string      `env:"JWT_AUDIENCES" envSeparator:","`
+	DenylistCleanup   time.Duration `env:"DENYLIST_CLEANUP,notEmpty" envDefault:"1m"`
+	TokenVersionTTL   time.Duration `env:"TOKEN_VERSION_TTL,notEmpty" envDefault:"5s"`
+	AccessTokenTTL    time.Duration `env:"ACCESS_TOKEN_TTL,notEmpty" envDefault:"15m"`
+	RefreshTokenTTL   time.Duration `env:"REFRESH_TOKEN_TTL,notEmpty" envDefault:"10h"`
+	RememberMeTTL     time.Duration `env:"REMEMBER_ME_TTL,notEmpty" envDefault:"720h"`
+	SessionMaxTTL     time.Duration `env:"SESSION_MAX_TTL,notEmpty" envDefault:"720h"`
+	TotpKey           string        `env:"TOTP_KEY,notEmpty"`
+	TotpIssuer        string        `env:"TOTP_ISSUER,notEmpty" envDefault:"User-Service"`
+	Argon2Memory      uint32        `env:"ARGON2_MEMORY,notEmpty" envDefault:"65536"`
+	Argon2Iterations  uint32        `env:"ARGON2_ITERATIONS,notEmpty" envDefault:"3"`
+	Argon2Parallelism uint8         `env:"ARGON2_PARALLELISM,notEmpty" envDefault:"2"`
+	Argon2SaltLength  uint32        `env:"ARGON2_SALT_LENGTH,notEmpty" envDefault:"16"`
+	Argon2KeyLength   uint32        `env:"ARGON2_KEY_LENGTH,notEmpty" envDefault:"32"`
+	BreachedPasswords string        `env:"BREACHED_PASSWORDS_DIR"`
+	BreachPolicy      string        `env:"BREACH_POLICY,notEmpty" envDefault:"reject"`
+	PasswordHistory   int           `env:"PASSWORD_HISTORY" envDefault:"5"`
+	LoginThreshold    int           `env:"LOCKOUT_LOGIN_THRESHOLD" envDefault:"5"`
+	IPThreshold       int           `env:"LOCKOUT_IP_THRESHOLD" envDefault:"20"`
+	LockoutBase       time.Duration `env:"LOCKOUT_BASE_LOCK,notEmpty" envDefault:"30s"`
+	LockoutMax        time.Duration `env:"LOCKOUT_MAX_LOCK,notEmpty" envDefault:"1h"`
+	LockoutWindow     time.Duration `env:"LOCKOUT_WINDOW,notEmpty" envDefault:"15m"`
+	LockoutCleanup    time.Duration `env:"LOCKOUT_CLEANUP,notEmpty" envDefault:"5m"`
+	RateLimitBackend  string        `env:"RATE_LIMIT_BACKEND,notEmpty" envDefault:"memory"`
+	RateLimits        []string      `env:"RATE_LIMITS" envSeparator:"," envDefault:"Signup=5/1m,Login=10/1m,Refresh=30/1m"`
+	RateLimitDefault  string        `env:"RATE_LIMIT_DEFAULT"`
+	RateLimitIP       string        `env:"RATE_LIMIT_IP" envDefault:"100/1m"`
+	RateLimitFailOpen bool          `env:"RATE_LIMIT_FAIL_OPEN" envDefault:"false"`
+	RateLimitCleanup  time.Duration `env:"RATE_LIMIT_CLEANUP,notEmpty" envDefault:"1m"`
+	MagicLinkURL      string        `env:"MAGIC_LINK_URL,notEmpty" envDefault:"http://localhost:3000/login/magic"`
+	PasswordResetURL  string        `env:"PASSWORD_RESET_URL,notEmpty" envDefault:"http://localhost:3000/password/reset"`
+	Notifier          string        `env:"NOTIFIER,notEmpty" envDefault:"file"`
+	NotifierFile      string        `env:"NOTIFIER_FILE"`
+	SMTPHost          string        `env:"SMTP_HOST" envDefault:"localhost"`
+	SMTPPort          string        `env:"SMTP_PORT" envDefault:"25"`
+	SMTPUser          string        `env:"SMTP_USER"`
+	SMTPPassword      string        `env:"SMTP_PASSWORD"`
+	SMTPFrom          string        `env:"SMTP_FROM" envDefault:"no-reply@localhost"`
+	TrustedProxies    []string      `env:"TRUSTED_PROXIES" envSeparator:","`
+	Port              string        `env:"PORT,notEmpty" envDefault:"10000"`
+	Host              string        `env:"HOST,notEmpty" envDefault:"localhost"`
+
+	// Per role overrides of token lifetimes, e.g. ADMIN_SESSION_MAX_TTL
+	AdminTokens   TokenLifetimes `envPrefix:"ADMIN_"`
+	UserTokens    TokenLifetimes `envPrefix:"USER_"`
+	ServiceTokens TokenLifetimes `envPrefix:"SERVICE_"`
+
+	// Rules for new passwords, e.g. PASSWORD_MIN_LENGTH
+	Password PasswordPolicy `envPrefix:"PASSWORD_"`
+}
+
+// TokenLifetimes overrides of token lifetimes for role, unset values fall back to defaults
+type TokenLifetimes struct {
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL"`
+	RememberMeTTL   time.Duration `env:"REMEMBER_ME_TTL"`
+	SessionMaxTTL   time.Duration `env:"SESSION_MAX_TTL"`
+}
+
+// PasswordPolicy rules for new passwords, zero values disable rule
+type PasswordPolicy struct {
+	MinEntropy     float64       `env:"MIN_ENTROPY" envDefault:"50"`
+	MinLength      int           `env:"MIN_LENGTH" envDefault:"8"`
+	MaxLength      int           `env:"MAX_LENGTH" envDefault:"128"`
+	ForbidUserInfo bool          `env:"FORBID_USER_INFO" envDefault:"true"`
+	RequireLower   bool          `env:"REQUIRE_LOWER"`
+	RequireUpper   bool          `env:"REQUIRE_UPPER"`
+	RequireDigit   bool          `env:"REQUIRE_DIGIT"`
+	RequireSpecial bool          `env:"REQUIRE_SPECIAL"`
+	MaxAge         time.Duration `env:"MAX_AGE"`
 }
 
 // NewMainConfig parsing config from environment
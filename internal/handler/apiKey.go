@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CreateAPIKey handler issue api key for current user
+func (h *User) CreateAPIKey(ctx context.Context, request *pr.CreateAPIKeyRequest) (response *pr.CreateAPIKeyResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - CreateAPIKey - claims not found")
+	}
+	if err = denyImpersonated(ctx, "CreateAPIKey"); err != nil {
+		return nil, err
+	}
+
+	var expires *time.Time
+	if request.Expires != nil {
+		t := request.Expires.AsTime()
+		expires = &t
+	}
+
+	var apiKey *model.APIKey
+	response = &pr.CreateAPIKeyResponse{}
+	response.Key, apiKey, err = h.service.CreateAPIKey(ctx, claims.ID, request.Name, request.Scopes, expires)
+	if err != nil {
+		err = fmt.Errorf("userHandler - CreateAPIKey - CreateAPIKey: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.ApiKey = toAPIKey(apiKey)
+
+	return
+}
+
+// ListAPIKeys handler api keys of current user
+func (h *User) ListAPIKeys(ctx context.Context, _ *pr.ListAPIKeysRequest) (response *pr.ListAPIKeysResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - ListAPIKeys - claims not found")
+	}
+
+	var apiKeys []*model.APIKey
+	apiKeys, err = h.service.ListAPIKeys(ctx, claims.ID)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ListAPIKeys - ListAPIKeys: %w", err)
+		logrus.Error(err)
+		return
+	}
+
+	response = &pr.ListAPIKeysResponse{ApiKeys: make([]*pr.APIKey, 0, len(apiKeys))}
+	for _, apiKey := range apiKeys {
+		response.ApiKeys = append(response.ApiKeys, toAPIKey(apiKey))
+	}
+
+	return
+}
+
+// RevokeAPIKey handler revoke api key of current user
+func (h *User) RevokeAPIKey(ctx context.Context, request *pr.RevokeAPIKeyRequest) (response *pr.RevokeAPIKeyResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - RevokeAPIKey - claims not found")
+	}
+	if err = denyImpersonated(ctx, "RevokeAPIKey"); err != nil {
+		return nil, err
+	}
+
+	response = &pr.RevokeAPIKeyResponse{}
+	err = h.service.RevokeAPIKey(ctx, claims.ID, request.Id)
+	if err != nil {
+		err = fmt.Errorf("userHandler - RevokeAPIKey - RevokeAPIKey: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+func toAPIKey(apiKey *model.APIKey) *pr.APIKey {
+	result := &pr.APIKey{
+		Id:      apiKey.ID,
+		Name:    apiKey.Name,
+		Prefix:  apiKey.Prefix,
+		Scopes:  apiKey.Scopes,
+		Created: timestamppb.New(apiKey.Created),
+	}
+	if apiKey.Expires != nil {
+		result.Expires = timestamppb.New(*apiKey.Expires)
+	}
+	if apiKey.LastUsed != nil {
+		result.LastUsed = timestamppb.New(*apiKey.LastUsed)
+	}
+
+	return result
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ImpersonateUser handler issue short lived access token of user to calling admin
+func (h *User) ImpersonateUser(ctx context.Context, request *pr.ImpersonateUserRequest) (response *pr.ImpersonateUserResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - ImpersonateUser - claims not found")
+	}
+
+	response = &pr.ImpersonateUserResponse{}
+	accessToken, expires, err := h.service.ImpersonateUser(ctx, claims.ID, request.UserId, request.Reason)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ImpersonateUser - ImpersonateUser: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.AccessToken = accessToken
+	response.Expires = timestamppb.New(expires)
+
+	logrus.WithFields(logrus.Fields{
+		"actor":  claims.ID,
+		"target": request.UserId,
+	}).Warn("userHandler - impersonation token issued")
+
+	return
+}
+
+// denyImpersonated rejecting destructive operations called with impersonation token
+func denyImpersonated(ctx context.Context, method string) error {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok || !claims.Impersonated() {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"method": method,
+		"caller": claims.ID,
+		"actor":  claims.Actor.Subject,
+	}).Warn("userHandler - access denied: impersonated")
+	return status.Error(codes.PermissionDenied, "operation is not allowed while impersonating")
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// UnlockUser handler forget failed login attempts and lock of user
+func (h *User) UnlockUser(ctx context.Context, request *pr.UnlockUserRequest) (response *pr.UnlockUserResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - UnlockUser - claims not found")
+	}
+
+	response = &pr.UnlockUserResponse{}
+	err = h.service.UnlockUser(ctx, claims.ID, request.UserId)
+	if err != nil {
+		err = fmt.Errorf("userHandler - UnlockUser - UnlockUser: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// loginStatus converting lockout to resource exhausted with retry delay and invalid credentials to unauthenticated,
+// both are returned alike for existing and unknown logins, other errors are returned as is
+func loginStatus(err error) error {
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		return status.Error(codes.Unauthenticated, service.ErrInvalidCredentials.Error())
+	}
+
+	var lockedErr *service.LoginLockedError
+	if !errors.As(err, &lockedErr) {
+		return err
+	}
+
+	st, detailsErr := status.New(codes.ResourceExhausted, lockedErr.Error()).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(lockedErr.RetryAfter),
+	})
+	if detailsErr != nil {
+		logrus.Error(fmt.Errorf("userHandler - loginStatus - WithDetails: %w", detailsErr))
+		return status.Error(codes.ResourceExhausted, lockedErr.Error())
+	}
+
+	return st.Err()
+}
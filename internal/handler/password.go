@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ChangePassword handler change password of current user, other sessions are ended
+func (h *User) ChangePassword(ctx context.Context, request *pr.ChangePasswordRequest) (response *pr.ChangePasswordResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - ChangePassword - claims not found")
+	}
+	if err = denyImpersonated(ctx, "ChangePassword"); err != nil {
+		return nil, err
+	}
+
+	response = &pr.ChangePasswordResponse{}
+	response.AccessToken, response.RefreshToken, err = h.service.ChangePassword(ctx, claims.ID, claims.SessionID,
+		request.CurrentPassword, request.NewPassword)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ChangePassword - ChangePassword: %w", err)
+		logrus.Error(err)
+		err = loginStatus(passwordStatus(err, "newPassword"))
+		return
+	}
+
+	return
+}
+
+// RequestPasswordReset handler send password reset link, success is returned for unknown logins too
+func (h *User) RequestPasswordReset(ctx context.Context, request *pr.RequestPasswordResetRequest) (response *pr.RequestPasswordResetResponse, err error) {
+	response = &pr.RequestPasswordResetResponse{}
+	err = h.service.RequestPasswordReset(ctx, request.Login)
+	if err != nil {
+		err = fmt.Errorf("userHandler - RequestPasswordReset - RequestPasswordReset: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// ResetPassword handler set new password with token from reset link
+func (h *User) ResetPassword(ctx context.Context, request *pr.ResetPasswordRequest) (response *pr.ResetPasswordResponse, err error) {
+	response = &pr.ResetPasswordResponse{}
+	err = h.service.ResetPassword(ctx, request.Token, request.NewPassword)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ResetPassword - ResetPassword: %w", err)
+		logrus.Error(err)
+		err = passwordStatus(err, "newPassword")
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// passwordStatus converting password policy violations to bad request details of field
+// and expired password to failed precondition, other errors are returned as is
+func passwordStatus(err error, field string) error {
+	if errors.Is(err, service.ErrPasswordExpired) {
+		return status.Error(codes.FailedPrecondition, "password expired, reset is required")
+	}
+
+	var policyErr *service.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return err
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range policyErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: v.Description,
+		})
+	}
+	st, detailsErr := status.New(codes.InvalidArgument, policyErr.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		logrus.Error(fmt.Errorf("userHandler - passwordStatus - WithDetails: %w", detailsErr))
+		return status.Error(codes.InvalidArgument, policyErr.Error())
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/OVantsevich/User-Service/internal/middleware"
+	"github.com/OVantsevich/User-Service/internal/model"
+	pr "github.com/OVantsevich/User-Service/proto"
+)
+
+// Policies access rules of user service methods
+func Policies() middleware.Policies {
+	return middleware.Policies{
+		method("Signup"):                 {Policy: middleware.PolicyPublic},
+		method("Login"):                  {Policy: middleware.PolicyPublic},
+		method("Refresh"):                {Policy: middleware.PolicyPublic},
+		method("GetJWKS"):                {Policy: middleware.PolicyPublic},
+		method("VerifySecondFactor"):     {Policy: middleware.PolicyPublic},
+		method("ClientCredentials"):      {Policy: middleware.PolicyPublic},
+		method("RequestMagicLink"):       {Policy: middleware.PolicyPublic},
+		method("ConsumeMagicLink"):       {Policy: middleware.PolicyPublic},
+		method("RequestPasswordReset"):   {Policy: middleware.PolicyPublic},
+		method("ResetPassword"):          {Policy: middleware.PolicyPublic},
+		method("Update"):                 {Policy: middleware.PolicySelf, Scope: model.ScopeProfileWrite},
+		method("Delete"):                 {Policy: middleware.PolicySelf, Scope: model.ScopeProfileWrite},
+		method("UserById"):               {Policy: middleware.PolicySelf, Scope: model.ScopeProfileRead},
+		method("ListSessions"):           {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSessions},
+		method("RevokeSession"):          {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSessions},
+		method("RevokeAllOtherSessions"): {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSessions},
+		method("Logout"):                 {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSessions},
+		method("ExchangeToken"):          {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeExchange},
+		method("IntrospectToken"):        {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeIntrospect},
+		method("ChangePassword"):         {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("EnrollTOTP"):             {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("ConfirmTOTP"):            {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("DisableTOTP"):            {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("CreateAPIKey"):           {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("ListAPIKeys"):            {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("RevokeAPIKey"):           {Policy: middleware.PolicyAuthenticated, Scope: model.ScopeSecurity},
+		method("CreateServiceAccount"):   {Policy: middleware.PolicyAdmin, Scope: model.ScopeAdmin},
+		method("DeleteServiceAccount"):   {Policy: middleware.PolicyAdmin, Scope: model.ScopeAdmin},
+		method("ImpersonateUser"):        {Policy: middleware.PolicyAdmin, Scope: model.ScopeAdmin},
+		method("RevokeAllTokens"):        {Policy: middleware.PolicyAdmin, Scope: model.ScopeAdmin},
+		method("UnlockUser"):             {Policy: middleware.PolicyAdmin, Scope: model.ScopeAdmin},
+	}
+}
+
+// method full name of user service method
+func method(name string) string {
+	return "/" + pr.UserService_ServiceDesc.ServiceName + "/" + name
+}
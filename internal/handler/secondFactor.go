@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EnrollTOTP handler start enrollment of authenticator app
+func (h *User) EnrollTOTP(ctx context.Context, _ *pr.EnrollTOTPRequest) (response *pr.EnrollTOTPResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - EnrollTOTP - claims not found")
+	}
+	if err = denyImpersonated(ctx, "EnrollTOTP"); err != nil {
+		return nil, err
+	}
+
+	var enrollment *model.TOTPEnrollment
+	enrollment, err = h.service.EnrollTOTP(ctx, claims.ID)
+	if err != nil {
+		err = fmt.Errorf("userHandler - EnrollTOTP - EnrollTOTP: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response = &pr.EnrollTOTPResponse{
+		Secret: enrollment.Secret,
+		Uri:    enrollment.URI,
+	}
+
+	return
+}
+
+// ConfirmTOTP handler enable second factor with first code from authenticator app
+func (h *User) ConfirmTOTP(ctx context.Context, request *pr.ConfirmTOTPRequest) (response *pr.ConfirmTOTPResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - ConfirmTOTP - claims not found")
+	}
+	if err = denyImpersonated(ctx, "ConfirmTOTP"); err != nil {
+		return nil, err
+	}
+
+	response = &pr.ConfirmTOTPResponse{}
+	response.RecoveryCodes, err = h.service.ConfirmTOTP(ctx, claims.ID, request.Code)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ConfirmTOTP - ConfirmTOTP: %w", err)
+		logrus.Error(err)
+		return
+	}
+
+	return
+}
+
+// DisableTOTP handler disable second factor
+func (h *User) DisableTOTP(ctx context.Context, request *pr.DisableTOTPRequest) (response *pr.DisableTOTPResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - DisableTOTP - claims not found")
+	}
+	if err = denyImpersonated(ctx, "DisableTOTP"); err != nil {
+		return nil, err
+	}
+
+	response = &pr.DisableTOTPResponse{}
+	err = h.service.DisableTOTP(ctx, claims.ID, request.Code)
+	if err != nil {
+		err = fmt.Errorf("userHandler - DisableTOTP - DisableTOTP: %w", err)
+		logrus.Error(err)
+		err = loginStatus(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// VerifySecondFactor handler finish login of user with second factor
+func (h *User) VerifySecondFactor(ctx context.Context, request *pr.VerifySecondFactorRequest) (response *pr.VerifySecondFactorResponse, err error) {
+	response = &pr.VerifySecondFactorResponse{}
+	response.AccessToken, response.RefreshToken, err = h.service.VerifySecondFactor(ctx, request.ChallengeToken, request.Code, newSession(ctx, ""))
+	if err != nil {
+		err = fmt.Errorf("userHandler - VerifySecondFactor - VerifySecondFactor: %w", err)
+		logrus.Error(err)
+		err = loginStatus(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateServiceAccount handler create service account owned by calling admin
+func (h *User) CreateServiceAccount(ctx context.Context, request *pr.CreateServiceAccountRequest) (response *pr.CreateServiceAccountResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - CreateServiceAccount - claims not found")
+	}
+
+	var account *model.ServiceAccount
+	response = &pr.CreateServiceAccountResponse{}
+	response.ClientSecret, account, err = h.service.CreateServiceAccount(ctx, claims.ID, request.Name, request.Role)
+	if err != nil {
+		if errors.Is(err, service.ErrServiceAccountRole) {
+			return nil, status.Error(codes.InvalidArgument, service.ErrServiceAccountRole.Error())
+		}
+		err = fmt.Errorf("userHandler - CreateServiceAccount - CreateServiceAccount: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Id = account.ID
+	response.ClientId = account.ClientID
+	response.Role = account.Role
+
+	return
+}
+
+// DeleteServiceAccount handler delete service account
+func (h *User) DeleteServiceAccount(ctx context.Context, request *pr.DeleteServiceAccountRequest) (response *pr.DeleteServiceAccountResponse, err error) {
+	response = &pr.DeleteServiceAccountResponse{}
+	err = h.service.DeleteServiceAccount(ctx, request.Id)
+	if err != nil {
+		err = fmt.Errorf("userHandler - DeleteServiceAccount - DeleteServiceAccount: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// ClientCredentials handler access token for service account
+func (h *User) ClientCredentials(ctx context.Context, request *pr.ClientCredentialsRequest) (response *pr.ClientCredentialsResponse, err error) {
+	response = &pr.ClientCredentialsResponse{}
+	response.AccessToken, err = h.service.ClientCredentials(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ClientCredentials - ClientCredentials: %w", err)
+		logrus.Error(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListSessions handler active sessions of current user
+func (h *User) ListSessions(ctx context.Context, _ *pr.ListSessionsRequest) (response *pr.ListSessionsResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - ListSessions - claims not found")
+	}
+
+	var sessions []*model.Session
+	sessions, err = h.service.ListSessions(ctx, claims.ID)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ListSessions - ListSessions: %w", err)
+		logrus.Error(err)
+		return
+	}
+
+	response = &pr.ListSessionsResponse{Sessions: make([]*pr.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, &pr.Session{
+			Id:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			Ip:         session.IP,
+			Created:    timestamppb.New(session.Created),
+			LastUsed:   timestamppb.New(session.LastUsed),
+			Current:    session.ID == claims.SessionID,
+		})
+	}
+
+	return
+}
+
+// RevokeSession handler revoke one session of current user
+func (h *User) RevokeSession(ctx context.Context, request *pr.RevokeSessionRequest) (response *pr.RevokeSessionResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - RevokeSession - claims not found")
+	}
+	if err = denyImpersonated(ctx, "RevokeSession"); err != nil {
+		return nil, err
+	}
+
+	response = &pr.RevokeSessionResponse{}
+	err = h.service.RevokeSession(ctx, claims.ID, request.SessionId)
+	if err != nil {
+		err = fmt.Errorf("userHandler - RevokeSession - RevokeSession: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// RevokeAllOtherSessions handler revoke all sessions of current user except the calling one
+func (h *User) RevokeAllOtherSessions(ctx context.Context, _ *pr.RevokeAllOtherSessionsRequest) (response *pr.RevokeAllOtherSessionsResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - RevokeAllOtherSessions - claims not found")
+	}
+	if err = denyImpersonated(ctx, "RevokeAllOtherSessions"); err != nil {
+		return nil, err
+	}
+	// api keys and exchanged tokens have no session, empty id would revoke every session
+	if claims.SessionID == "" {
+		return nil, status.Error(codes.FailedPrecondition, "userHandler - RevokeAllOtherSessions - token has no session")
+	}
+
+	response = &pr.RevokeAllOtherSessionsResponse{}
+	err = h.service.RevokeOtherSessions(ctx, claims.ID, claims.SessionID)
+	if err != nil {
+		err = fmt.Errorf("userHandler - RevokeAllOtherSessions - RevokeOtherSessions: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// RevokeAllTokens handler invalidate all tokens and sessions of user
+func (h *User) RevokeAllTokens(ctx context.Context, request *pr.RevokeAllTokensRequest) (response *pr.RevokeAllTokensResponse, err error) {
+	response = &pr.RevokeAllTokensResponse{}
+	err = h.service.RevokeAllTokens(ctx, request.UserId)
+	if err != nil {
+		err = fmt.Errorf("userHandler - RevokeAllTokens - RevokeAllTokens: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// Logout handler end current session
+func (h *User) Logout(ctx context.Context, _ *pr.LogoutRequest) (response *pr.LogoutResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - Logout - claims not found")
+	}
+	if err = denyImpersonated(ctx, "Logout"); err != nil {
+		return nil, err
+	}
+
+	response = &pr.LogoutResponse{}
+	err = h.service.Logout(ctx, claims)
+	if err != nil {
+		err = fmt.Errorf("userHandler - Logout - Logout: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// newSession session with user agent from request metadata and client ip resolved by middleware
+func newSession(ctx context.Context, deviceName string) *model.Session {
+	session := &model.Session{DeviceName: deviceName, IP: service.ClientIPFromContext(ctx)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+			session.UserAgent = userAgent[0]
+		}
+	}
+
+	return session
+}
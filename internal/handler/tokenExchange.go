@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+	pr "github.com/OVantsevich/User-Service/proto"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ExchangeToken handler exchange subject token for downscoped token acted on by calling service account
+func (h *User) ExchangeToken(ctx context.Context, request *pr.ExchangeTokenRequest) (response *pr.ExchangeTokenResponse, err error) {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "userHandler - ExchangeToken - claims not found")
+	}
+
+	response = &pr.ExchangeTokenResponse{}
+	var expires time.Time
+	response.AccessToken, expires, response.Scopes, err = h.service.ExchangeToken(ctx, claims, request.SubjectToken, request.Audience,
+		request.Scopes)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ExchangeToken - ExchangeToken: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Expires = timestamppb.New(expires)
+
+	return
+}
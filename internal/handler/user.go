@@ -5,35 +5,70 @@ import (
 	"context"
 	"fmt"
 	"github.com/OVantsevich/User-Service/internal/model"
+	"github.com/OVantsevich/User-Service/internal/service"
 	pr "github.com/OVantsevich/User-Service/proto"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // UserService service interface for user handler
 //
 //go:generate mockery --name=UserService --case=underscore --output=./mocks
 type UserService interface {
-	Signup(ctx context.Context, user *model.User) (string, string, *model.User, error)
-	Login(ctx context.Context, login, password string) (string, string, error)
-	Refresh(ctx context.Context, id, userRefreshToken string) (string, string, error)
+	Signup(ctx context.Context, user *model.User, session *model.Session) (string, string, *model.User, error)
+	Login(ctx context.Context, login, password string, session *model.Session) (string, string, string, error)
+	Refresh(ctx context.Context, id, userRefreshToken string, client *model.Session) (string, string, error)
 	Update(ctx context.Context, id string, user *model.User) error
 	Delete(ctx context.Context, id string) error
+	ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) (string, string, error)
+	RequestPasswordReset(ctx context.Context, login string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	UnlockUser(ctx context.Context, actorID, userID string) error
 
 	GetByLogin(ctx context.Context, login string) (*model.User, error)
 	GetByID(ctx context.Context, id string) (*model.User, error)
+
+	JWKS(ctx context.Context) *model.JWKS
+
+	ListSessions(ctx context.Context, userID string) ([]*model.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error
+	RevokeAllTokens(ctx context.Context, userID string) error
+	Logout(ctx context.Context, claims *service.CustomClaims) error
+	IntrospectToken(ctx context.Context, token string) (*model.Introspection, error)
+
+	EnrollTOTP(ctx context.Context, userID string) (*model.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID, code string) error
+	VerifySecondFactor(ctx context.Context, challengeToken, code string, session *model.Session) (string, string, error)
+
+	CreateAPIKey(ctx context.Context, userID, name string, scopes []string, expires *time.Time) (string, *model.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id string) error
+
+	CreateServiceAccount(ctx context.Context, ownerID, name, role string) (string, *model.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id string) error
+	ClientCredentials(ctx context.Context, clientID, clientSecret string) (string, error)
+
+	RequestMagicLink(ctx context.Context, login string) error
+	ConsumeMagicLink(ctx context.Context, token string, session *model.Session) (string, string, string, error)
+
+	ImpersonateUser(ctx context.Context, actorID, userID, reason string) (string, time.Time, error)
+	ExchangeToken(ctx context.Context, caller *service.CustomClaims, subjectToken, audience string, scopes []string) (string, time.Time, []string, error)
 }
 
 // User handler
 type User struct {
 	pr.UnimplementedUserServiceServer
 	service UserService
-	jwtKey  string
 }
 
 // NewUserHandlerClassic new user handler
-func NewUserHandlerClassic(s UserService, key string) *User {
-	return &User{service: s, jwtKey: key}
+func NewUserHandlerClassic(s UserService) *User {
+	return &User{service: s}
 }
 
 // Signup handler signup
@@ -48,10 +83,11 @@ func (h *User) Signup(ctx context.Context, request *pr.SignupRequest) (response
 
 	var userResponse *model.User
 	response = &pr.SignupResponse{}
-	response.AccessToken, response.RefreshToken, userResponse, err = h.service.Signup(ctx, user)
+	response.AccessToken, response.RefreshToken, userResponse, err = h.service.Signup(ctx, user, newSession(ctx, request.DeviceName))
 	if err != nil {
 		err = fmt.Errorf("userHandler - Signup - Signup: %w", err)
 		logrus.Error(err)
+		err = passwordStatus(err, "password")
 		return
 	}
 	response.User = &pr.User{
@@ -67,13 +103,18 @@ func (h *User) Signup(ctx context.Context, request *pr.SignupRequest) (response
 
 // Login handler login
 func (h *User) Login(ctx context.Context, request *pr.LoginRequest) (response *pr.LoginResponse, err error) {
+	session := newSession(ctx, request.DeviceName)
+	session.Remember = request.RememberMe
+
 	response = &pr.LoginResponse{}
-	response.AccessToken, response.RefreshToken, err = h.service.Login(ctx, request.Login, request.Password)
+	response.AccessToken, response.RefreshToken, response.ChallengeToken, err = h.service.Login(ctx, request.Login, request.Password, session)
 	if err != nil {
 		err = fmt.Errorf("userHandler - Login - Login: %w", err)
 		logrus.Error(err)
+		err = loginStatus(passwordStatus(err, "password"))
 		return
 	}
+	response.SecondFactorRequired = response.ChallengeToken != ""
 
 	return
 }
@@ -81,10 +122,11 @@ func (h *User) Login(ctx context.Context, request *pr.LoginRequest) (response *p
 // Refresh handler refresh
 func (h *User) Refresh(ctx context.Context, request *pr.RefreshRequest) (response *pr.RefreshResponse, err error) {
 	response = &pr.RefreshResponse{}
-	response.AccessToken, response.RefreshToken, err = h.service.Refresh(ctx, request.Id, request.RefreshToken)
+	response.AccessToken, response.RefreshToken, err = h.service.Refresh(ctx, request.Id, request.RefreshToken, newSession(ctx, ""))
 	if err != nil {
 		err = fmt.Errorf("userHandler - Refresh - Refresh: %w", err)
 		logrus.Error(err)
+		err = passwordStatus(err, "password")
 		return
 	}
 
@@ -93,7 +135,12 @@ func (h *User) Refresh(ctx context.Context, request *pr.RefreshRequest) (respons
 
 // Update handler update
 func (h *User) Update(ctx context.Context, request *pr.UpdateRequest) (response *pr.UpdateResponse, err error) {
-	//var claims = ctx.Value("user").(*service.CustomClaims)
+	if err = authorizeOwner(ctx, "Update", request.ID); err != nil {
+		return nil, err
+	}
+	if err = denyImpersonated(ctx, "Update"); err != nil {
+		return nil, err
+	}
 
 	user := &model.User{
 		Email: request.Email,
@@ -114,7 +161,12 @@ func (h *User) Update(ctx context.Context, request *pr.UpdateRequest) (response
 
 // Delete handler delete
 func (h *User) Delete(ctx context.Context, request *pr.Request) (response *pr.DeleteResponse, err error) {
-	//var claims = ctx.Value("user").(*service.CustomClaims)
+	if err = authorizeOwner(ctx, "Delete", request.ID); err != nil {
+		return nil, err
+	}
+	if err = denyImpersonated(ctx, "Delete"); err != nil {
+		return nil, err
+	}
 
 	response = &pr.DeleteResponse{}
 	err = h.service.Delete(ctx, request.ID)
@@ -130,13 +182,9 @@ func (h *User) Delete(ctx context.Context, request *pr.Request) (response *pr.De
 
 // UserByID handler user by login
 func (h *User) UserByID(ctx context.Context, request *pr.UserByIdRequest) (response *pr.UserByIdResponse, err error) {
-	//var claims = ctx.Value("user").(*service.CustomClaims)
-	//
-	//if claims.Role != "admin" {
-	//	err = fmt.Errorf("access denied")
-	//	logrus.Error(err)
-	//	return
-	//}
+	if err = authorizeOwner(ctx, "UserByID", request.ID); err != nil {
+		return nil, err
+	}
 
 	response = &pr.UserByIdResponse{}
 	var user *model.User
@@ -156,3 +204,110 @@ func (h *User) UserByID(ctx context.Context, request *pr.UserByIdRequest) (respo
 
 	return
 }
+
+// GetJWKS handler public keys for token verification
+func (h *User) GetJWKS(ctx context.Context, _ *pr.JWKSRequest) (response *pr.JWKSResponse, err error) {
+	jwks := h.service.JWKS(ctx)
+
+	response = &pr.JWKSResponse{Keys: make([]*pr.JWK, 0, len(jwks.Keys))}
+	for _, key := range jwks.Keys {
+		response.Keys = append(response.Keys, &pr.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		})
+	}
+
+	return
+}
+
+// IntrospectToken handler state of token for other services, callers must be service accounts with introspect scope
+func (h *User) IntrospectToken(ctx context.Context, request *pr.IntrospectTokenRequest) (response *pr.IntrospectTokenResponse, err error) {
+	var introspection *model.Introspection
+	introspection, err = h.service.IntrospectToken(ctx, request.Token)
+	if err != nil {
+		err = fmt.Errorf("userHandler - IntrospectToken - IntrospectToken: %w", err)
+		logrus.Error(err)
+		return
+	}
+
+	response = &pr.IntrospectTokenResponse{Active: introspection.Active}
+	if !introspection.Active {
+		return
+	}
+	response.Sub = introspection.Subject
+	response.Role = introspection.Role
+	if !introspection.ExpiresAt.IsZero() {
+		response.Exp = introspection.ExpiresAt.Unix()
+	}
+	if !introspection.IssuedAt.IsZero() {
+		response.Iat = introspection.IssuedAt.Unix()
+	}
+	response.Jti = introspection.JTI
+	response.Iss = introspection.Issuer
+	response.Aud = introspection.Audience
+	response.Scopes = introspection.Scopes
+	response.ClientId = introspection.ClientID
+	if introspection.Actor != nil {
+		response.Actor = introspection.Actor.Subject
+	}
+
+	return
+}
+
+// authorizeOwner allowing caller to act only on own account, admins can act on any account
+func authorizeOwner(ctx context.Context, method, id string) error {
+	claims, ok := service.ClaimsFromContext(ctx)
+	if !ok {
+		logrus.WithFields(logrus.Fields{"method": method, "target": id}).Warn("userHandler - access denied: claims not found")
+		return status.Error(codes.Unauthenticated, "authorization required")
+	}
+	if claims.Role == model.RoleAdmin || claims.ID == id {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"method": method,
+		"caller": claims.ID,
+		"role":   claims.Role,
+		"target": id,
+	}).Warn("userHandler - access denied: not an owner")
+	return status.Error(codes.PermissionDenied, "access denied")
+}
+
+// RequestMagicLink handler send login link, success is returned for unknown logins too
+func (h *User) RequestMagicLink(ctx context.Context, request *pr.RequestMagicLinkRequest) (response *pr.RequestMagicLinkResponse, err error) {
+	response = &pr.RequestMagicLinkResponse{}
+	err = h.service.RequestMagicLink(ctx, request.Login)
+	if err != nil {
+		err = fmt.Errorf("userHandler - RequestMagicLink - RequestMagicLink: %w", err)
+		logrus.Error(err)
+		return
+	}
+	response.Success = true
+
+	return
+}
+
+// ConsumeMagicLink handler login with magic link token
+func (h *User) ConsumeMagicLink(ctx context.Context, request *pr.ConsumeMagicLinkRequest) (response *pr.ConsumeMagicLinkResponse, err error) {
+	session := newSession(ctx, request.DeviceName)
+	session.Remember = request.RememberMe
+
+	response = &pr.ConsumeMagicLinkResponse{}
+	response.AccessToken, response.RefreshToken, response.ChallengeToken, err = h.service.ConsumeMagicLink(ctx, request.Token, session)
+	if err != nil {
+		err = fmt.Errorf("userHandler - ConsumeMagicLink - ConsumeMagicLink: %w", err)
+		logrus.Error(err)
+		err = passwordStatus(err, "password")
+		return
+	}
+	response.SecondFactorRequired = response.ChallengeToken != ""
+
+	return
+}
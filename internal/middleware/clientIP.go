@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// TrustedProxies networks of proxies whose x-forwarded-for header is honoured
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parsing proxies written as ip or cidr
+func ParseTrustedProxies(proxies []string) (TrustedProxies, error) {
+	trusted := make(TrustedProxies, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("clientIP - ParseTrustedProxies - invalid ip %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("clientIP - ParseTrustedProxies - ParseCIDR: %w", err)
+		}
+		trusted = append(trusted, network)
+	}
+
+	return trusted, nil
+}
+
+// contains check if ip belongs to one of trusted proxies
+func (t TrustedProxies) contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range t {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP resolving ip of client and attaching it to context, x-forwarded-for is honoured only
+// when peer is trusted proxy and client is the nearest address in it which is not a trusted proxy
+func ClientIP(proxies TrustedProxies) []grpc.ServerOption {
+	c := &clientIP{proxies: proxies}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(c.unary),
+		grpc.ChainStreamInterceptor(c.stream),
+	}
+}
+
+type clientIP struct {
+	proxies TrustedProxies
+}
+
+func (c *clientIP) unary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(service.NewContextWithClientIP(ctx, c.resolve(ctx)), req)
+}
+
+func (c *clientIP) stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &clientIPStream{
+		ServerStream: ss,
+		ctx:          service.NewContextWithClientIP(ss.Context(), c.resolve(ss.Context())),
+	})
+}
+
+func (c *clientIP) resolve(ctx context.Context) string {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	if !c.proxies.contains(ip) {
+		return ip
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ip
+	}
+	var hops []string
+	for _, forwarded := range md.Get("x-forwarded-for") {
+		hops = append(hops, strings.Split(forwarded, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !c.proxies.contains(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// clientIPStream server stream with client ip in context
+type clientIPStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context context with client ip
+func (s *clientIPStream) Context() context.Context {
+	return s.ctx
+}
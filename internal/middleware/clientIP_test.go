@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientIPResolve(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	c := &clientIP{proxies: proxies}
+
+	tests := []struct {
+		name      string
+		peer      string
+		forwarded string
+		want      string
+	}{
+		{name: "untrusted peer", peer: "203.0.113.7", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "trusted peer", peer: "10.1.2.3", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "spoofed hops", peer: "10.1.2.3", forwarded: "1.1.1.1, 198.51.100.1, 192.168.1.1", want: "198.51.100.1"},
+		{name: "no header", peer: "192.168.1.1", want: "192.168.1.1"},
+		{name: "garbage hop", peer: "10.1.2.3", forwarded: "evil", want: "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(tt.peer), Port: 5000}})
+			if tt.forwarded != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", tt.forwarded))
+			}
+			if got := c.resolve(ctx); got != tt.want {
+				t.Fatalf("resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,58 +4,274 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"github.com/OVantsevich/User-Service/internal/model"
 	"github.com/OVantsevich/User-Service/internal/service"
 
-	"github.com/golang-jwt/jwt/v4"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-// JwtAuth checking token and attaching it to context
-func JwtAuth(keyFunc func(token *jwt.Token) (interface{}, error)) grpc.ServerOption {
-	return grpc.UnaryInterceptor(func(ctx context.Context,
-		req interface{},
-		info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler) (interface{}, error) {
-		if info.FullMethod != "/UserService/Signup" && info.FullMethod != "/UserService/Login" && info.FullMethod != "/UserService/Refresh" {
-			md, ok := metadata.FromIncomingContext(ctx)
-			if !ok {
-				return nil, status.Errorf(codes.InvalidArgument, "Retrieving metadata is failed")
-			}
-
-			authHeader, ok := md["authorization"]
-			if !ok {
-				return nil, status.Errorf(codes.Unauthenticated, "Authorization token is not supplied")
-			}
-
-			token := authHeader[0]
-
-			claims, err := verify(token, keyFunc)
-			if err != nil {
-				return nil, err
-			}
-			ctx = context.WithValue(ctx, "user", claims)
-		}
+// Denylist access tokens which were revoked before expiration
+type Denylist interface {
+	IsDenied(ctx context.Context, jti string) (bool, error)
+}
+
+// TokenVersions current token versions of users, tokens with older version are rejected
+type TokenVersions interface {
+	IsCurrent(ctx context.Context, userID string, version int) (bool, error)
+}
+
+// ServiceAccounts checking that service account of token was not deleted
+type ServiceAccounts interface {
+	CheckServiceAccount(ctx context.Context, id string) error
+}
+
+// Sessions checking that session of token was not revoked
+type Sessions interface {
+	CheckSession(ctx context.Context, id string) error
+}
 
-		h, err := handler(ctx, req)
+// Verifier checking token in configured format and returning its claims
+type Verifier interface {
+	Verify(token string) (*service.CustomClaims, error)
+}
+
+// APIKeys authenticator of api keys, which are accepted instead of jwt
+type APIKeys interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*service.CustomClaims, error)
+}
 
-		return h, err
+// Scheme of authorization header
+const bearerScheme = "bearer"
+
+// JwtAuth checking token or api key according to method policy and attaching claims to context,
+// only access tokens of issuer intended for audience are accepted, tokens are checked by verifier of configured format
+func JwtAuth(verifier Verifier, issuer, audience string, denylist Denylist, versions TokenVersions, apiKeys APIKeys,
+	accounts ServiceAccounts, sessions Sessions, policies Policies) []grpc.ServerOption {
+	a := &auth{
+		verifier: verifier,
+		issuer:   issuer,
+		audience: audience,
+		denylist: denylist,
+		versions: versions,
+		apiKeys:  apiKeys,
+		accounts: accounts,
+		sessions: sessions,
+		policies: policies,
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(a.unary),
+		grpc.ChainStreamInterceptor(a.stream),
+	}
+}
+
+type auth struct {
+	verifier Verifier
+	issuer   string
+	audience string
+	denylist Denylist
+	versions TokenVersions
+	apiKeys  APIKeys
+	accounts ServiceAccounts
+	sessions Sessions
+	policies Policies
+}
+
+func (a *auth) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	rule := a.policies[info.FullMethod]
+	claims, err := a.authenticate(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		return handler(ctx, req)
+	}
+	if err = authorizeRequest(rule.Policy, claims, req); err != nil {
+		return nil, err
+	}
+
+	return handler(service.NewContextWithClaims(ctx, claims), req)
+}
+
+func (a *auth) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	rule := a.policies[info.FullMethod]
+	claims, err := a.authenticate(ss.Context(), rule)
+	if err != nil {
+		return err
+	}
+	if claims == nil {
+		return handler(srv, ss)
+	}
+
+	return handler(srv, &authStream{
+		ServerStream: ss,
+		ctx:          service.NewContextWithClaims(ss.Context(), claims),
+		policy:       rule.Policy,
+		claims:       claims,
 	})
 }
 
-func verify(token string, keyFunc func(token *jwt.Token) (interface{}, error)) (claims *service.CustomClaims, err error) {
-	claims = &service.CustomClaims{}
+// authenticate verifying token required by rule, nil claims are returned for public methods
+func (a *auth) authenticate(ctx context.Context, rule Rule) (*service.CustomClaims, error) {
+	switch rule.Policy {
+	case PolicyPublic:
+		return nil, nil
+	case PolicyAuthenticated, PolicySelf, PolicyAdmin:
+	default:
+		return nil, status.Errorf(codes.PermissionDenied, "Method has no access policy")
+	}
+
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims *service.CustomClaims
+	if strings.HasPrefix(token, model.APIKeyPrefix) {
+		claims, err = a.apiKeys.AuthenticateAPIKey(ctx, token)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "API key is invalid")
+		}
+	} else if claims, err = a.authenticateJWT(ctx, token); err != nil {
+		return nil, err
+	}
+
+	if rule.Policy == PolicyAdmin && claims.Role != model.RoleAdmin {
+		return nil, status.Errorf(codes.PermissionDenied, "Method is allowed only for admin")
+	}
+	if rule.Scope != "" && !model.HasScope(scopes(claims), rule.Scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "Scope %q is required", rule.Scope)
+	}
+
+	return claims, nil
+}
+
+func (a *auth) authenticateJWT(ctx context.Context, token string) (*service.CustomClaims, error) {
+	claims, err := verify(token, a.verifier, a.issuer, a.audience)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Authorization token is invalid")
+	}
+
+	denied, err := a.denylist.IsDenied(ctx, claims.RegisteredClaims.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Checking token revocation is failed")
+	}
+	if denied {
+		return nil, status.Errorf(codes.Unauthenticated, "Authorization token is revoked")
+	}
+
+	// service accounts have no token version, tokens of deleted accounts are rejected instead
+	if claims.ClientID != "" {
+		if err = a.accounts.CheckServiceAccount(ctx, claims.ID); err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "Service account is deleted")
+		}
+		return claims, nil
+	}
+
+	current, err := a.versions.IsCurrent(ctx, claims.ID, claims.Version)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Checking token version is failed")
+	}
+	if !current {
+		return nil, status.Errorf(codes.Unauthenticated, "Authorization token is revoked")
+	}
+
+	// access tokens of revoked sessions are rejected like in introspection
+	if claims.SessionID != "" {
+		if err = a.sessions.CheckSession(ctx, claims.SessionID); err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "Session is revoked")
+		}
+	}
+
+	return claims, nil
+}
+
+// scopes granted to caller, tokens without scopes get all scopes of role
+func scopes(claims *service.CustomClaims) []string {
+	if claims.Scopes == nil {
+		return model.RoleScopes(claims.Role)
+	}
+	return claims.Scopes
+}
+
+// authorizeRequest checking that self only request targets caller, admins may target anyone
+func authorizeRequest(policy Policy, claims *service.CustomClaims, req interface{}) error {
+	if policy != PolicySelf || claims.Role == model.RoleAdmin {
+		return nil
+	}
+
+	target, ok := req.(interface{ GetID() string })
+	if !ok || target.GetID() != claims.ID {
+		return status.Errorf(codes.PermissionDenied, "Method is allowed only for own account")
+	}
 
-	_, err = jwt.ParseWithClaims(
-		token,
-		claims,
-		keyFunc,
-	)
+	return nil
+}
+
+// bearerToken token from authorization header, "Bearer " prefix is optional
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "Retrieving metadata is failed")
+	}
+
+	authHeader := md.Get("authorization")
+	if len(authHeader) == 0 || authHeader[0] == "" {
+		return "", status.Errorf(codes.Unauthenticated, "Authorization token is not supplied")
+	}
+
+	scheme, token, found := strings.Cut(strings.TrimSpace(authHeader[0]), " ")
+	if !found {
+		return scheme, nil
+	}
+	if !strings.EqualFold(scheme, bearerScheme) {
+		return "", status.Errorf(codes.Unauthenticated, "Authorization scheme %q is not supported", scheme)
+	}
+
+	return strings.TrimSpace(token), nil
+}
+
+// verify checking signature, validity, type, issuer and audience of token
+func verify(token string, verifier Verifier, issuer, audience string) (claims *service.CustomClaims, err error) {
+	claims, err = verifier.Verify(token)
 	if err != nil {
-		err = fmt.Errorf("invalid token: %w", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if claims.Type != service.TokenTypeAccess {
+		return nil, fmt.Errorf("invalid token: type %q is not accepted", claims.Type)
+	}
+	if !claims.VerifyIssuer(issuer, true) {
+		return nil, fmt.Errorf("invalid token: issuer %q is not accepted", claims.Issuer)
+	}
+	if !claims.VerifyAudience(audience, true) {
+		return nil, fmt.Errorf("invalid token: audience %q is required", audience)
 	}
 
 	return
 }
+
+// authStream server stream with claims in context
+type authStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	policy Policy
+	claims *service.CustomClaims
+}
+
+// Context context with claims
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg receiving message and checking that it targets caller
+func (s *authStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return authorizeRequest(s.policy, s.claims, m)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+// Policy access policy of method
+type Policy int
+
+// Access policies, method without policy is denied
+const (
+	// PolicyPublic method can be called without token
+	PolicyPublic Policy = iota + 1
+	// PolicyAuthenticated method requires valid token
+	PolicyAuthenticated
+	// PolicySelf method requires valid token and request ID of caller, admin can pass any ID
+	PolicySelf
+	// PolicyAdmin method requires valid token with admin role
+	PolicyAdmin
+)
+
+// Rule access rule of method, scope is required from token when set
+type Rule struct {
+	Policy Policy
+	Scope  string
+}
+
+// Policies access rules by full method name
+type Policies map[string]Rule
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Limiter token buckets, zero is returned when request is allowed, otherwise time after which it can be retried
+type Limiter interface {
+	Allow(ctx context.Context, key string, rate float64, burst int) (time.Duration, error)
+}
+
+// Limit rate of method, bucket is refilled with rate tokens per second up to burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Limits rate limits by full method name
+type Limits map[string]Limit
+
+// ParseLimit parsing limit written as count/period, e.g. 10/1m allows bursts of 10 requests refilled over a minute
+func ParseLimit(spec string) (Limit, error) {
+	count, period, found := strings.Cut(strings.TrimSpace(spec), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("rateLimit - ParseLimit - %q is not count/period", spec)
+	}
+	burst, err := strconv.Atoi(count)
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("rateLimit - ParseLimit - invalid count %q", count)
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("rateLimit - ParseLimit - invalid period %q", period)
+	}
+
+	return Limit{Rate: float64(burst) / duration.Seconds(), Burst: burst}, nil
+}
+
+// RateLimit limiting calls of methods with limits, every method has own buckets keyed by api key,
+// authenticated user or client ip, methods without limit use default limit unless it is nil,
+// it must follow JwtAuth to see callers, requests are rejected when limiter fails unless failOpen is set
+func RateLimit(limiter Limiter, limits Limits, defaultLimit *Limit, failOpen bool) []grpc.ServerOption {
+	r := &rateLimit{limiter: limiter, limits: limits, defaultLimit: defaultLimit, failOpen: failOpen, key: callerKey}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(r.unary),
+		grpc.ChainStreamInterceptor(r.stream),
+	}
+}
+
+// IPRateLimit limiting calls of all methods together by client ip, it must precede JwtAuth
+// so unauthenticated floods are throttled before tokens are checked
+func IPRateLimit(limiter Limiter, limit Limit, failOpen bool) []grpc.ServerOption {
+	r := &rateLimit{limiter: limiter, defaultLimit: &limit, failOpen: failOpen, key: ipKey}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(r.unary),
+		grpc.ChainStreamInterceptor(r.stream),
+	}
+}
+
+type rateLimit struct {
+	limiter      Limiter
+	limits       Limits
+	defaultLimit *Limit
+	failOpen     bool
+	key          func(ctx context.Context, method string) string
+}
+
+func (r *rateLimit) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := r.allow(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (r *rateLimit) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := r.allow(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// allow taking token of caller for method, failure of limiter is logged
+// and request is rejected as unavailable or allowed when failing open
+func (r *rateLimit) allow(ctx context.Context, method string) error {
+	limit, ok := r.limits[method]
+	if !ok {
+		if r.defaultLimit == nil {
+			return nil
+		}
+		limit = *r.defaultLimit
+	}
+
+	retryAfter, err := r.limiter.Allow(ctx, r.key(ctx, method), limit.Rate, limit.Burst)
+	if err != nil {
+		logrus.Error(fmt.Errorf("rateLimit - allow - Allow: %w", err))
+		if r.failOpen {
+			return nil
+		}
+		return status.Errorf(codes.Unavailable, "Rate limit cannot be checked")
+	}
+	if retryAfter <= 0 {
+		return nil
+	}
+
+	st, err := status.New(codes.ResourceExhausted, "Rate limit is exceeded").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+	if err != nil {
+		return status.Errorf(codes.ResourceExhausted, "Rate limit is exceeded")
+	}
+	return st.Err()
+}
+
+// callerKey bucket of method for api key, authenticated user or client ip of caller
+func callerKey(ctx context.Context, method string) string {
+	if claims, ok := service.ClaimsFromContext(ctx); ok {
+		if claims.Type == service.TokenTypeAPIKey {
+			return method + "|key:" + claims.RegisteredClaims.ID
+		}
+		return method + "|user:" + claims.ID
+	}
+
+	return method + "|" + ipKey(ctx, method)
+}
+
+// ipKey bucket shared by all methods for client ip, the same ip as sessions and lockout see
+func ipKey(ctx context.Context, _ string) string {
+	if ip := service.ClientIPFromContext(ctx); ip != "" {
+		return "ip:" + ip
+	}
+	return "ip:unknown"
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type failingLimiter struct {
+	keys []string
+}
+
+func (l *failingLimiter) Allow(_ context.Context, key string, _ float64, _ int) (time.Duration, error) {
+	l.keys = append(l.keys, key)
+	return 0, errors.New("limiter is down")
+}
+
+func TestRateLimitFailure(t *testing.T) {
+	ctx := service.NewContextWithClientIP(context.Background(), "198.51.100.1")
+	limit := Limit{Rate: 1, Burst: 1}
+
+	closed := &rateLimit{limiter: &failingLimiter{}, defaultLimit: &limit, key: ipKey}
+	if err := closed.allow(ctx, "/UserService/Login"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("allow() = %v, want Unavailable", err)
+	}
+
+	open := &rateLimit{limiter: &failingLimiter{}, defaultLimit: &limit, failOpen: true, key: ipKey}
+	if err := open.allow(ctx, "/UserService/Login"); err != nil {
+		t.Fatalf("allow() = %v, want nil when failing open", err)
+	}
+}
+
+func TestCallerKey(t *testing.T) {
+	ctx := service.NewContextWithClientIP(context.Background(), "198.51.100.1")
+	if got, want := callerKey(ctx, "/m"), "/m|ip:198.51.100.1"; got != want {
+		t.Fatalf("callerKey() = %q, want %q", got, want)
+	}
+
+	key := &service.CustomClaims{ID: "user", Type: service.TokenTypeAPIKey}
+	key.RegisteredClaims.ID = "key"
+	if got, want := callerKey(service.NewContextWithClaims(ctx, key), "/m"), "/m|key:key"; got != want {
+		t.Fatalf("callerKey() = %q, want %q", got, want)
+	}
+
+	user := &service.CustomClaims{ID: "user", Type: service.TokenTypeAccess}
+	if got, want := callerKey(service.NewContextWithClaims(ctx, user), "/m"), "/m|user:user"; got != want {
+		t.Fatalf("callerKey() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Prefix of api keys, it distinguishes them from jwt
+const APIKeyPrefix = "usk_"
+
+// APIKey long-lived credential of user for automation, only hash of key is stored
+type APIKey struct {
+	ID       string     `json:"id"`
+	UserID   string     `json:"userId"`
+	Name     string     `json:"name"`
+	Prefix   string     `json:"prefix"`
+	KeyHash  string     `json:"-"`
+	Scopes   []string   `json:"scopes"`
+	Expires  *time.Time `json:"expires" format:"date-time"`
+	Revoked  bool       `json:"revoked"`
+	LastUsed *time.Time `json:"lastUsed" format:"date-time"`
+	Created  time.Time  `json:"created" format:"date-time"`
+}
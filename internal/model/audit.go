@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Actions recorded in audit log
+const (
+	AuditActionImpersonate = "impersonate"
+	AuditActionUnlock      = "unlock"
+)
+
+// AuditEvent action performed by one account on behalf of or against another
+type AuditEvent struct {
+	ID        string     `json:"id"`
+	ActorID   string     `json:"actorId"`
+	SubjectID string     `json:"subjectId"`
+	Action    string     `json:"action"`
+	Reason    string     `json:"reason"`
+	TokenID   string     `json:"tokenId"`
+	Expires   *time.Time `json:"expires,omitempty" format:"date-time"`
+	Created   time.Time  `json:"created" format:"date-time"`
+}
+
+// Actor party acting on behalf of token subject as described in RFC 8693,
+// nested actor is the one which delegated to this actor
+type Actor struct {
+	Subject string `json:"sub"`
+	Actor   *Actor `json:"act,omitempty"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Introspection state of token as described in RFC 7662
+type Introspection struct {
+	Active    bool      `json:"active"`
+	Subject   string    `json:"sub,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	ExpiresAt time.Time `json:"exp,omitempty" format:"date-time"`
+	IssuedAt  time.Time `json:"iat,omitempty" format:"date-time"`
+	JTI       string    `json:"jti,omitempty"`
+	Issuer    string    `json:"iss,omitempty"`
+	Audience  []string  `json:"aud,omitempty"`
+	Scopes    []string  `json:"scope,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Actor     *Actor    `json:"act,omitempty"`
+}
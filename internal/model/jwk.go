@@ -0,0 +1,18 @@
+package model
+
+// JWK public part of signing key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS set of json web keys
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// Kinds of keys failed login attempts are counted by
+const (
+	LoginAttemptLogin        = "login"
+	LoginAttemptIP           = "ip"
+	LoginAttemptSecondFactor = "second_factor"
+)
@@ -0,0 +1,8 @@
+package model
+
+// Notification message delivered to user
+type Notification struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
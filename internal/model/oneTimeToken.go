@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Purposes of one time tokens
+const (
+	PurposeMagicLink     = "magic_link"
+	PurposePasswordReset = "password_reset"
+)
+
+// OneTimeToken single use token sent to user, only hash of token is stored
+type OneTimeToken struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Purpose   string    `json:"purpose"`
+	TokenHash string    `json:"-"`
+	Used      bool      `json:"used"`
+	Expires   time.Time `json:"expires" format:"date-time"`
+	Created   time.Time `json:"created" format:"date-time"`
+}
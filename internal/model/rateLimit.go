@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// TokenBucket bucket of rate limit, it is refilled with rate tokens per second up to burst
+// and every request takes one token
+type TokenBucket struct {
+	Tokens  float64
+	Rate    float64
+	Burst   int
+	Updated time.Time
+}
+
+// NewTokenBucket full bucket
+func NewTokenBucket(rate float64, burst int, now time.Time) *TokenBucket {
+	return &TokenBucket{Tokens: float64(burst), Rate: rate, Burst: burst, Updated: now}
+}
+
+// Take taking token from bucket, zero is returned when token was taken,
+// otherwise time after which token will be available
+func (b *TokenBucket) Take(now time.Time) time.Duration {
+	b.refill(now)
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return 0
+	}
+	if b.Rate <= 0 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration((1 - b.Tokens) / b.Rate * float64(time.Second))
+}
+
+// Full bucket is refilled up to burst, it is the same as absent bucket
+func (b *TokenBucket) Full(now time.Time) bool {
+	b.refill(now)
+	return b.Tokens >= float64(b.Burst)
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens += elapsed.Seconds() * b.Rate
+		b.Updated = now
+	}
+	if b.Tokens > float64(b.Burst) {
+		b.Tokens = float64(b.Burst)
+	}
+}
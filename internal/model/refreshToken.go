@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Types of token events
+const (
+	TokenEventReuseDetected = "refresh_token_reuse"
+)
+
+// RefreshToken issued refresh token, only hash of token is stored
+type RefreshToken struct {
+	ID        string    `json:"id"`
+	FamilyID  string    `json:"familyId"`
+	UserID    string    `json:"userId"`
+	TokenHash string    `json:"-"`
+	Used      bool      `json:"used"`
+	Revoked   bool      `json:"revoked"`
+	Expires   time.Time `json:"expires" format:"date-time"`
+	Created   time.Time `json:"created" format:"date-time"`
+	Updated   time.Time `json:"updated" format:"date-time"`
+}
+
+// TokenEvent security relevant event in token family
+type TokenEvent struct {
+	ID       string    `json:"id"`
+	UserID   string    `json:"userId"`
+	FamilyID string    `json:"familyId"`
+	Type     string    `json:"type"`
+	Created  time.Time `json:"created" format:"date-time"`
+}
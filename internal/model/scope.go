@@ -0,0 +1,36 @@
+package model
+
+// Scopes of access granted to tokens and api keys
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeSessions     = "sessions"
+	ScopeSecurity     = "security"
+	ScopeAdmin        = "admin"
+	ScopeIntrospect   = "introspect"
+	ScopeExchange     = "exchange"
+)
+
+// RoleScopes scopes available to role, service accounts have no user account to manage
+// and only they can introspect and exchange tokens of others
+func RoleScopes(role string) []string {
+	switch role {
+	case RoleService:
+		return []string{ScopeIntrospect, ScopeExchange}
+	case RoleAdmin:
+		return []string{ScopeProfileRead, ScopeProfileWrite, ScopeSessions, ScopeSecurity, ScopeAdmin}
+	default:
+		return []string{ScopeProfileRead, ScopeProfileWrite, ScopeSessions, ScopeSecurity}
+	}
+}
+
+// HasScope check if scope is in scopes
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
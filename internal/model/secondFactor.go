@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// LoginChallenge pending login of user with second factor, only hash of token is stored
+type LoginChallenge struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	TokenHash  string    `json:"-"`
+	DeviceName string    `json:"deviceName"`
+	Remember   bool      `json:"remember"`
+	Attempts   int       `json:"attempts"`
+	Used       bool      `json:"used"`
+	Expires    time.Time `json:"expires" format:"date-time"`
+	Created    time.Time `json:"created" format:"date-time"`
+}
+
+// TOTPEnrollment data for adding account to authenticator app
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Prefix of client ids of service accounts
+const ClientIDPrefix = "sa_"
+
+// ServiceAccount machine client authenticating with client credentials, only hash of secret is stored
+type ServiceAccount struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	ClientID   string    `json:"clientId"`
+	SecretHash string    `json:"-"`
+	Role       string    `json:"role"`
+	OwnerID    string    `json:"ownerId"`
+	Created    time.Time `json:"created" format:"date-time"`
+	Updated    time.Time `json:"updated" format:"date-time"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session user session on one device, refresh tokens of session share its id as family,
+// sessions started with remember me get longer refresh tokens
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	DeviceName string    `json:"deviceName"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	Revoked    bool      `json:"revoked"`
+	Remember   bool      `json:"remember"`
+	Created    time.Time `json:"created" format:"date-time"`
+	LastUsed   time.Time `json:"lastUsed" format:"date-time"`
+}
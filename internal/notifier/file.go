@@ -0,0 +1,53 @@
+// Package notifier delivery of notifications to users
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// File notifier for local use, notifications are appended to file or written to log when path is empty
+type File struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creating new File notifier
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Send write notification
+func (n *File) Send(_ context.Context, notification *model.Notification) error {
+	if n.path == "" {
+		logrus.WithFields(logrus.Fields{
+			"to":      notification.To,
+			"subject": notification.Subject,
+		}).Info(notification.Body)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("fileNotifier - Send - OpenFile: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), notification.To,
+		notification.Subject, notification.Body)
+	if err != nil {
+		return fmt.Errorf("fileNotifier - Send - Fprintf: %w", err)
+	}
+
+	return nil
+}
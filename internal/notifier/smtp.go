@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+)
+
+// Timeout of whole smtp conversation when context has no deadline
+const smtpTimeout = time.Second * 30
+
+// SMTP notifier sending notifications by email
+type SMTP struct {
+	host string
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTP creating new SMTP notifier, plain auth is used when user is set
+func NewSMTP(host, port, user, password, from string) *SMTP {
+	n := &SMTP{host: host, addr: net.JoinHostPort(host, port), from: from}
+	if user != "" {
+		n.auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return n
+}
+
+// Send send notification as plain text email, conversation is bounded by deadline of context
+func (n *SMTP) Send(ctx context.Context, notification *model.Notification) error {
+	to := headerValue(notification.To)
+	msg := strings.Join([]string{
+		"From: " + headerValue(n.from),
+		"To: " + to,
+		"Subject: " + headerValue(notification.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		notification.Body,
+	}, "\r\n")
+
+	if err := n.sendMail(ctx, to, []byte(msg)); err != nil {
+		return fmt.Errorf("smtpNotifier - Send - sendMail: %w", err)
+	}
+
+	return nil
+}
+
+// sendMail the same conversation as smtp.SendMail over connection with deadline
+func (n *SMTP) sendMail(ctx context.Context, to string, msg []byte) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn, err := (&net.Dialer{Deadline: deadline}).DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - DialContext: %w", err)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return fmt.Errorf("smtpNotifier - sendMail - SetDeadline: %w", err)
+	}
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtpNotifier - sendMail - NewClient: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: n.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("smtpNotifier - sendMail - StartTLS: %w", err)
+		}
+	}
+	if n.auth != nil {
+		if err = c.Auth(n.auth); err != nil {
+			return fmt.Errorf("smtpNotifier - sendMail - Auth: %w", err)
+		}
+	}
+	if err = c.Mail(n.from); err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - Mail: %w", err)
+	}
+	if err = c.Rcpt(to); err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - Rcpt: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - Data: %w", err)
+	}
+	if _, err = w.Write(msg); err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - Write: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - Close: %w", err)
+	}
+
+	if err = c.Quit(); err != nil {
+		return fmt.Errorf("smtpNotifier - sendMail - Quit: %w", err)
+	}
+
+	return nil
+}
+
+// headerValue value without line breaks, so it cannot inject headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
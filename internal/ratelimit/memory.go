@@ -0,0 +1,52 @@
+// Package ratelimit in-memory storage of rate limit buckets
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+)
+
+// Memory buckets of single replica kept in process
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*model.TokenBucket
+}
+
+// NewMemory creating new Memory storage
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*model.TokenBucket)}
+}
+
+// TakeToken take token from bucket of key, returns time after which token will be available if bucket is empty
+func (m *Memory) TakeToken(_ context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = model.NewTokenBucket(rate, burst, now)
+		m.buckets[key] = bucket
+	}
+	bucket.Rate, bucket.Burst = rate, burst
+
+	return bucket.Take(now), nil
+}
+
+// DeleteFullRateLimits delete buckets which are refilled up to burst
+func (m *Memory) DeleteFullRateLimits(_ context.Context) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, bucket := range m.buckets {
+		if bucket.Full(now) {
+			delete(m.buckets, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// How often last usage of api key is written
+const apiKeyTouchInterval = time.Minute
+
+// APIKey postgres entity
+type APIKey struct {
+	Pool *pgxpool.Pool
+}
+
+// NewAPIKey creating new APIKey repository
+func NewAPIKey(pool *pgxpool.Pool) *APIKey {
+	return &APIKey{Pool: pool}
+}
+
+// CreateAPIKey create api key
+func (r *APIKey) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	key.Created = time.Now()
+	_, err := r.Pool.Exec(ctx,
+		`insert into api_keys (id, user_id, "name", prefix, key_hash, scopes, expires, created) values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.Expires, key.Created)
+	if err != nil {
+		return fmt.Errorf("apiKey - CreateAPIKey - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeyByHash get api key by hash
+func (r *APIKey) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	key := model.APIKey{}
+	err := r.Pool.QueryRow(ctx, `select id, user_id, "name", prefix, key_hash, scopes, expires, revoked, last_used, created
+									from api_keys where key_hash = $1`, hash).Scan(
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes, &key.Expires, &key.Revoked, &key.LastUsed, &key.Created)
+	if err != nil {
+		return nil, fmt.Errorf("apiKey - GetAPIKeyByHash - Scan: %w", err)
+	}
+
+	return &key, nil
+}
+
+// GetAPIKeysByUser get not revoked api keys of user
+func (r *APIKey) GetAPIKeysByUser(ctx context.Context, userID string) ([]*model.APIKey, error) {
+	rows, err := r.Pool.Query(ctx, `select id, user_id, "name", prefix, key_hash, scopes, expires, revoked, last_used, created
+									from api_keys where user_id = $1 and revoked=false order by created desc`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("apiKey - GetAPIKeysByUser - Query: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key := &model.APIKey{}
+		err = rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes, &key.Expires, &key.Revoked,
+			&key.LastUsed, &key.Created)
+		if err != nil {
+			return nil, fmt.Errorf("apiKey - GetAPIKeysByUser - Scan: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("apiKey - GetAPIKeysByUser - Err: %w", err)
+	}
+
+	return keys, nil
+}
+
+// TouchAPIKey update last usage of api key, writes are throttled to one per interval
+func (r *APIKey) TouchAPIKey(ctx context.Context, id string) error {
+	now := time.Now()
+	_, err := r.Pool.Exec(ctx, "update api_keys set last_used=$1 where id=$2 and (last_used is null or last_used<$3)",
+		now, id, now.Add(-apiKeyTouchInterval))
+	if err != nil {
+		return fmt.Errorf("apiKey - TouchAPIKey - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAPIKey revoke api key of user
+func (r *APIKey) RevokeAPIKey(ctx context.Context, userID, id string) error {
+	var idCheck string
+	err := r.Pool.QueryRow(ctx, "update api_keys set revoked=true where id=$1 and user_id=$2 and revoked=false returning id",
+		id, userID).Scan(&idCheck)
+	if err != nil {
+		return fmt.Errorf("apiKey - RevokeAPIKey - Scan: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAPIKeysByUser revoke all api keys of user
+func (r *APIKey) RevokeAPIKeysByUser(ctx context.Context, userID string) error {
+	_, err := r.Pool.Exec(ctx, "update api_keys set revoked=true where user_id=$1 and revoked=false", userID)
+	if err != nil {
+		return fmt.Errorf("apiKey - RevokeAPIKeysByUser - Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Audit postgres entity
+type Audit struct {
+	Pool *pgxpool.Pool
+}
+
+// NewAudit creating new Audit repository
+func NewAudit(pool *pgxpool.Pool) *Audit {
+	return &Audit{Pool: pool}
+}
+
+// CreateAuditEvent create audit event
+func (r *Audit) CreateAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	event.Created = time.Now()
+	_, err := r.Pool.Exec(ctx,
+		`insert into audit_log (id, actor_id, subject_id, action, reason, token_id, expires, created) values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		event.ID, event.ActorID, event.SubjectID, event.Action, event.Reason, event.TokenID, event.Expires, event.Created)
+	if err != nil {
+		return fmt.Errorf("audit - CreateAuditEvent - Exec: %w", err)
+	}
+
+	return nil
+}
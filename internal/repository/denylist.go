@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Denylist postgres entity
+type Denylist struct {
+	Pool *pgxpool.Pool
+}
+
+// NewDenylist creating new Denylist repository
+func NewDenylist(pool *pgxpool.Pool) *Denylist {
+	return &Denylist{Pool: pool}
+}
+
+// AddToDenylist add token id to denylist
+func (r *Denylist) AddToDenylist(ctx context.Context, jti string, expires time.Time) error {
+	_, err := r.Pool.Exec(ctx, "insert into token_denylist (jti, expires) values ($1, $2) on conflict (jti) do nothing",
+		jti, expires)
+	if err != nil {
+		return fmt.Errorf("denylist - AddToDenylist - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// IsDenylisted check if token id is in denylist
+func (r *Denylist) IsDenylisted(ctx context.Context, jti string) (bool, error) {
+	var denied bool
+	err := r.Pool.QueryRow(ctx, "select exists(select 1 from token_denylist where jti=$1 and expires>$2)",
+		jti, time.Now()).Scan(&denied)
+	if err != nil {
+		return false, fmt.Errorf("denylist - IsDenylisted - Scan: %w", err)
+	}
+
+	return denied, nil
+}
+
+// DeleteExpiredDenylist delete entries of already expired tokens
+func (r *Denylist) DeleteExpiredDenylist(ctx context.Context) error {
+	_, err := r.Pool.Exec(ctx, "delete from token_denylist where expires<=$1", time.Now())
+	if err != nil {
+		return fmt.Errorf("denylist - DeleteExpiredDenylist - Exec: %w", err)
+	}
+
+	return nil
+}
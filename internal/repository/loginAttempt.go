@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginAttempt postgres entity
+type LoginAttempt struct {
+	Pool *pgxpool.Pool
+}
+
+// NewLoginAttempt creating new LoginAttempt repository
+func NewLoginAttempt(pool *pgxpool.Pool) *LoginAttempt {
+	return &LoginAttempt{Pool: pool}
+}
+
+// GetLoginLock get time until which key is locked, zero time if key is not locked
+func (r *LoginAttempt) GetLoginLock(ctx context.Context, kind, key string) (time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.Pool.QueryRow(ctx, "select max(locked_until) from login_attempts where kind=$1 and key=$2",
+		kind, key).Scan(&lockedUntil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loginAttempt - GetLoginLock - Scan: %w", err)
+	}
+	if lockedUntil == nil {
+		return time.Time{}, nil
+	}
+
+	return *lockedUntil, nil
+}
+
+// AddLoginFailure count failed attempt of key, failures older than window are forgotten,
+// returns number of failures in a row
+func (r *LoginAttempt) AddLoginFailure(ctx context.Context, kind, key string, window time.Duration) (int, error) {
+	now := time.Now()
+	var failures int
+	err := r.Pool.QueryRow(ctx, `insert into login_attempts (kind, key, failures, last_failure) values ($1, $2, 1, $3)
+									on conflict (kind, key) do update set
+										failures = case when login_attempts.last_failure < $4 then 1 else login_attempts.failures + 1 end,
+										last_failure = $3
+									returning failures`,
+		kind, key, now, now.Add(-window)).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("loginAttempt - AddLoginFailure - Scan: %w", err)
+	}
+
+	return failures, nil
+}
+
+// LockLogin lock key until time
+func (r *LoginAttempt) LockLogin(ctx context.Context, kind, key string, until time.Time) error {
+	_, err := r.Pool.Exec(ctx, "update login_attempts set locked_until=$1 where kind=$2 and key=$3", until, kind, key)
+	if err != nil {
+		return fmt.Errorf("loginAttempt - LockLogin - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// ResetLoginAttempts forget failed attempts and lock of key
+func (r *LoginAttempt) ResetLoginAttempts(ctx context.Context, kind, key string) error {
+	_, err := r.Pool.Exec(ctx, "delete from login_attempts where kind=$1 and key=$2", kind, key)
+	if err != nil {
+		return fmt.Errorf("loginAttempt - ResetLoginAttempts - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredLoginAttempts delete unlocked keys without failures since time
+func (r *LoginAttempt) DeleteExpiredLoginAttempts(ctx context.Context, before time.Time) error {
+	_, err := r.Pool.Exec(ctx, "delete from login_attempts where last_failure<$1 and (locked_until is null or locked_until<=$2)",
+		before, time.Now())
+	if err != nil {
+		return fmt.Errorf("loginAttempt - DeleteExpiredLoginAttempts - Exec: %w", err)
+	}
+
+	return nil
+}
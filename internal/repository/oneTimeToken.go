@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OneTimeToken postgres entity
+type OneTimeToken struct {
+	Pool *pgxpool.Pool
+}
+
+// NewOneTimeToken creating new OneTimeToken repository
+func NewOneTimeToken(pool *pgxpool.Pool) *OneTimeToken {
+	return &OneTimeToken{Pool: pool}
+}
+
+// CreateOneTimeToken create one time token
+func (r *OneTimeToken) CreateOneTimeToken(ctx context.Context, token *model.OneTimeToken) error {
+	token.Created = time.Now()
+	_, err := r.Pool.Exec(ctx,
+		`insert into one_time_tokens (id, user_id, purpose, token_hash, expires, created) values ($1, $2, $3, $4, $5, $6)`,
+		token.ID, token.UserID, token.Purpose, token.TokenHash, token.Expires, token.Created)
+	if err != nil {
+		return fmt.Errorf("oneTimeToken - CreateOneTimeToken - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetOneTimeToken get active token without using it
+func (r *OneTimeToken) GetOneTimeToken(ctx context.Context, purpose, hash string) (*model.OneTimeToken, error) {
+	token := model.OneTimeToken{}
+	err := r.Pool.QueryRow(ctx, `select id, user_id, purpose, token_hash, used, expires, created from one_time_tokens
+									where token_hash=$1 and purpose=$2 and used=false and expires>$3`,
+		hash, purpose, time.Now()).Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.Used, &token.Expires, &token.Created)
+	if err != nil {
+		return nil, fmt.Errorf("oneTimeToken - GetOneTimeToken - Scan: %w", err)
+	}
+
+	return &token, nil
+}
+
+// UseOneTimeToken mark active token as used and return it
+func (r *OneTimeToken) UseOneTimeToken(ctx context.Context, purpose, hash string) (*model.OneTimeToken, error) {
+	token := model.OneTimeToken{}
+	err := r.Pool.QueryRow(ctx, `update one_time_tokens set used=true
+									where token_hash=$1 and purpose=$2 and used=false and expires>$3
+									returning id, user_id, purpose, token_hash, used, expires, created`,
+		hash, purpose, time.Now()).Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.Used, &token.Expires, &token.Created)
+	if err != nil {
+		return nil, fmt.Errorf("oneTimeToken - UseOneTimeToken - Scan: %w", err)
+	}
+
+	return &token, nil
+}
+
+// RevokeOneTimeTokens mark all active tokens of user as used
+func (r *OneTimeToken) RevokeOneTimeTokens(ctx context.Context, userID string) error {
+	_, err := r.Pool.Exec(ctx, "update one_time_tokens set used=true where user_id=$1 and used=false", userID)
+	if err != nil {
+		return fmt.Errorf("oneTimeToken - RevokeOneTimeTokens - Exec: %w", err)
+	}
+
+	return nil
+}
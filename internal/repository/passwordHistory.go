@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PasswordHistory postgres entity
+type PasswordHistory struct {
+	Pool *pgxpool.Pool
+}
+
+// NewPasswordHistory creating new PasswordHistory repository
+func NewPasswordHistory(pool *pgxpool.Pool) *PasswordHistory {
+	return &PasswordHistory{Pool: pool}
+}
+
+// AddPasswordHistory add password hash to history of user keeping only the latest keep hashes
+func (r *PasswordHistory) AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error {
+	err := pgx.BeginFunc(ctx, r.Pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "insert into password_history (id, user_id, password_hash, created) values ($1, $2, $3, $4)",
+			uuid.New().String(), userID, hash, time.Now())
+		if err != nil {
+			return fmt.Errorf("passwordHistory - AddPasswordHistory - Exec: %w", err)
+		}
+		_, err = tx.Exec(ctx, `delete from password_history where user_id=$1 and id not in
+									(select id from password_history where user_id=$1 order by created desc limit $2)`,
+			userID, keep)
+		if err != nil {
+			return fmt.Errorf("passwordHistory - AddPasswordHistory - Exec: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("passwordHistory - AddPasswordHistory - BeginFunc: %w", err)
+	}
+
+	return nil
+}
+
+// GetPasswordHistory get the latest password hashes of user
+func (r *PasswordHistory) GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	rows, err := r.Pool.Query(ctx, "select password_hash from password_history where user_id=$1 order by created desc limit $2",
+		userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("passwordHistory - GetPasswordHistory - Query: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("passwordHistory - GetPasswordHistory - Scan: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("passwordHistory - GetPasswordHistory - Err: %w", err)
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RateLimit postgres entity, buckets are shared by all replicas
+type RateLimit struct {
+	Pool *pgxpool.Pool
+}
+
+// NewRateLimit creating new RateLimit repository
+func NewRateLimit(pool *pgxpool.Pool) *RateLimit {
+	return &RateLimit{Pool: pool}
+}
+
+// TakeToken take token from bucket of key, returns time after which token will be available if bucket is empty
+func (r *RateLimit) TakeToken(ctx context.Context, key string, rate float64, burst int) (retryAfter time.Duration, err error) {
+	err = pgx.BeginFunc(ctx, r.Pool, func(tx pgx.Tx) error {
+		now := time.Now()
+		_, err := tx.Exec(ctx, "insert into rate_limits (key, tokens, rate, burst, updated) values ($1, $2, $3, $4, $5) on conflict (key) do nothing",
+			key, float64(burst), rate, burst, now)
+		if err != nil {
+			return fmt.Errorf("rateLimit - TakeToken - Exec: %w", err)
+		}
+
+		bucket := &model.TokenBucket{Rate: rate, Burst: burst}
+		err = tx.QueryRow(ctx, "select tokens, updated from rate_limits where key=$1 for update", key).Scan(
+			&bucket.Tokens, &bucket.Updated)
+		if err != nil {
+			return fmt.Errorf("rateLimit - TakeToken - Scan: %w", err)
+		}
+		retryAfter = bucket.Take(now)
+
+		_, err = tx.Exec(ctx, "update rate_limits set tokens=$1, rate=$2, burst=$3, updated=$4 where key=$5",
+			bucket.Tokens, rate, burst, bucket.Updated, key)
+		if err != nil {
+			return fmt.Errorf("rateLimit - TakeToken - Exec: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("rateLimit - TakeToken - BeginFunc: %w", err)
+	}
+
+	return retryAfter, nil
+}
+
+// DeleteFullRateLimits delete buckets which are refilled up to burst
+func (r *RateLimit) DeleteFullRateLimits(ctx context.Context) error {
+	_, err := r.Pool.Exec(ctx, "delete from rate_limits where tokens + extract(epoch from ($1 - updated)) * rate >= burst",
+		time.Now())
+	if err != nil {
+		return fmt.Errorf("rateLimit - DeleteFullRateLimits - Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RefreshToken postgres entity
+type RefreshToken struct {
+	Pool *pgxpool.Pool
+}
+
+// NewRefreshToken creating new RefreshToken repository
+func NewRefreshToken(pool *pgxpool.Pool) *RefreshToken {
+	return &RefreshToken{Pool: pool}
+}
+
+// CreateRefreshToken create refresh token
+func (r *RefreshToken) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	token.Created = time.Now()
+	token.Updated = time.Now()
+	_, err := r.Pool.Exec(ctx,
+		`insert into refresh_tokens (id, family_id, user_id, token_hash, expires, created, updated) values ($1, $2, $3, $4, $5, $6, $7)`,
+		token.ID, token.FamilyID, token.UserID, token.TokenHash, token.Expires, token.Created, token.Updated)
+	if err != nil {
+		return fmt.Errorf("refreshToken - CreateRefreshToken - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefreshTokenByHash get refresh token by hash
+func (r *RefreshToken) GetRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	token := model.RefreshToken{}
+	err := r.Pool.QueryRow(ctx, `select id, family_id, user_id, token_hash, used, revoked, expires, created, updated
+									from refresh_tokens where token_hash = $1`, hash).Scan(
+		&token.ID, &token.FamilyID, &token.UserID, &token.TokenHash, &token.Used, &token.Revoked, &token.Expires, &token.Created, &token.Updated)
+	if err != nil {
+		return nil, fmt.Errorf("refreshToken - GetRefreshTokenByHash - Scan: %w", err)
+	}
+
+	return &token, nil
+}
+
+// UseRefreshToken mark refresh token as used, false is returned if it was already used or revoked
+func (r *RefreshToken) UseRefreshToken(ctx context.Context, id string) (bool, error) {
+	tag, err := r.Pool.Exec(ctx, "update refresh_tokens set used=true, updated=$1 where id=$2 and used=false and revoked=false",
+		time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("refreshToken - UseRefreshToken - Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RevokeFamily revoke all refresh tokens of family
+func (r *RefreshToken) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.Pool.Exec(ctx, "update refresh_tokens set revoked=true, updated=$1 where family_id=$2 and revoked=false",
+		time.Now(), familyID)
+	if err != nil {
+		return fmt.Errorf("refreshToken - RevokeFamily - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// CreateTokenEvent create token event
+func (r *RefreshToken) CreateTokenEvent(ctx context.Context, event *model.TokenEvent) error {
+	event.Created = time.Now()
+	_, err := r.Pool.Exec(ctx, `insert into token_events (id, user_id, family_id, "type", created) values ($1, $2, $3, $4, $5)`,
+		event.ID, event.UserID, event.FamilyID, event.Type, event.Created)
+	if err != nil {
+		return fmt.Errorf("refreshToken - CreateTokenEvent - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherFamilies revoke refresh tokens of all user families except one
+func (r *RefreshToken) RevokeOtherFamilies(ctx context.Context, userID, exceptFamilyID string) error {
+	_, err := r.Pool.Exec(ctx, "update refresh_tokens set revoked=true, updated=$1 where user_id=$2 and family_id<>$3 and revoked=false",
+		time.Now(), userID, exceptFamilyID)
+	if err != nil {
+		return fmt.Errorf("refreshToken - RevokeOtherFamilies - Exec: %w", err)
+	}
+
+	return nil
+}
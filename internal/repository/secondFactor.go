@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Maximum number of codes checked against one login challenge
+const maxChallengeAttempts = 5
+
+// SecondFactor postgres entity
+type SecondFactor struct {
+	Pool *pgxpool.Pool
+}
+
+// NewSecondFactor creating new SecondFactor repository
+func NewSecondFactor(pool *pgxpool.Pool) *SecondFactor {
+	return &SecondFactor{Pool: pool}
+}
+
+// ReplaceRecoveryCodes replace all recovery codes of user
+func (r *SecondFactor) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	err := pgx.BeginFunc(ctx, r.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "delete from recovery_codes where user_id=$1", userID); err != nil {
+			return fmt.Errorf("secondFactor - ReplaceRecoveryCodes - Exec: %w", err)
+		}
+		for _, hash := range hashes {
+			_, err := tx.Exec(ctx, "insert into recovery_codes (id, user_id, code_hash) values ($1, $2, $3)",
+				uuid.New().String(), userID, hash)
+			if err != nil {
+				return fmt.Errorf("secondFactor - ReplaceRecoveryCodes - Exec: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("secondFactor - ReplaceRecoveryCodes - BeginFunc: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode mark recovery code as used, false is returned if there is no unused code
+func (r *SecondFactor) UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error) {
+	tag, err := r.Pool.Exec(ctx, "update recovery_codes set used=true where user_id=$1 and code_hash=$2 and used=false",
+		userID, hash)
+	if err != nil {
+		return false, fmt.Errorf("secondFactor - UseRecoveryCode - Exec: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteRecoveryCodes delete all recovery codes of user
+func (r *SecondFactor) DeleteRecoveryCodes(ctx context.Context, userID string) error {
+	_, err := r.Pool.Exec(ctx, "delete from recovery_codes where user_id=$1", userID)
+	if err != nil {
+		return fmt.Errorf("secondFactor - DeleteRecoveryCodes - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// CreateChallenge create login challenge
+func (r *SecondFactor) CreateChallenge(ctx context.Context, challenge *model.LoginChallenge) error {
+	challenge.Created = time.Now()
+	_, err := r.Pool.Exec(ctx,
+		`insert into login_challenges (id, user_id, token_hash, device_name, remember, expires, created) values ($1, $2, $3, $4, $5, $6, $7)`,
+		challenge.ID, challenge.UserID, challenge.TokenHash, challenge.DeviceName, challenge.Remember, challenge.Expires, challenge.Created)
+	if err != nil {
+		return fmt.Errorf("secondFactor - CreateChallenge - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// AttemptChallenge count attempt against active challenge and return it
+func (r *SecondFactor) AttemptChallenge(ctx context.Context, hash string) (*model.LoginChallenge, error) {
+	challenge := model.LoginChallenge{}
+	err := r.Pool.QueryRow(ctx, `update login_challenges set attempts=attempts+1
+									where token_hash=$1 and used=false and expires>$2 and attempts<$3
+									returning id, user_id, token_hash, device_name, remember, attempts, used, expires, created`,
+		hash, time.Now(), maxChallengeAttempts).Scan(
+		&challenge.ID, &challenge.UserID, &challenge.TokenHash, &challenge.DeviceName, &challenge.Remember, &challenge.Attempts, &challenge.Used,
+		&challenge.Expires, &challenge.Created)
+	if err != nil {
+		return nil, fmt.Errorf("secondFactor - AttemptChallenge - Scan: %w", err)
+	}
+
+	return &challenge, nil
+}
+
+// CompleteChallenge mark challenge as used, false is returned if it was already used
+func (r *SecondFactor) CompleteChallenge(ctx context.Context, id string) (bool, error) {
+	tag, err := r.Pool.Exec(ctx, "update login_challenges set used=true where id=$1 and used=false", id)
+	if err != nil {
+		return false, fmt.Errorf("secondFactor - CompleteChallenge - Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// CompleteChallengeWithRecoveryCode mark recovery code and challenge as used in one transaction,
+// false is returned if there is no unused code, nothing is used if challenge was already used
+func (r *SecondFactor) CompleteChallengeWithRecoveryCode(ctx context.Context, id, userID, hash string) (bool, error) {
+	var used bool
+	err := pgx.BeginFunc(ctx, r.Pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "update recovery_codes set used=true where user_id=$1 and code_hash=$2 and used=false",
+			userID, hash)
+		if err != nil {
+			return fmt.Errorf("secondFactor - CompleteChallengeWithRecoveryCode - Exec: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+
+		tag, err = tx.Exec(ctx, "update login_challenges set used=true where id=$1 and used=false", id)
+		if err != nil {
+			return fmt.Errorf("secondFactor - CompleteChallengeWithRecoveryCode - Exec: %w", err)
+		}
+		if tag.RowsAffected() != 1 {
+			return fmt.Errorf("secondFactor - CompleteChallengeWithRecoveryCode - challenge already used")
+		}
+		used = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("secondFactor - CompleteChallengeWithRecoveryCode - BeginFunc: %w", err)
+	}
+
+	return used, nil
+}
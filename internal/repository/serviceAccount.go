@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ServiceAccount postgres entity
+type ServiceAccount struct {
+	Pool *pgxpool.Pool
+}
+
+// NewServiceAccount creating new ServiceAccount repository
+func NewServiceAccount(pool *pgxpool.Pool) *ServiceAccount {
+	return &ServiceAccount{Pool: pool}
+}
+
+// CreateServiceAccount create service account
+func (r *ServiceAccount) CreateServiceAccount(ctx context.Context, account *model.ServiceAccount) error {
+	account.Created = time.Now()
+	account.Updated = time.Now()
+	_, err := r.Pool.Exec(ctx,
+		`insert into service_accounts (id, "name", client_id, secret_hash, "role", owner_id, created, updated) values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		account.ID, account.Name, account.ClientID, account.SecretHash, account.Role, account.OwnerID, account.Created, account.Updated)
+	if err != nil {
+		return fmt.Errorf("serviceAccount - CreateServiceAccount - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetServiceAccountByClientID get service account by client id
+func (r *ServiceAccount) GetServiceAccountByClientID(ctx context.Context, clientID string) (*model.ServiceAccount, error) {
+	account := model.ServiceAccount{}
+	err := r.Pool.QueryRow(ctx, `select id, "name", client_id, secret_hash, "role", owner_id, created, updated
+									from service_accounts where client_id = $1 and deleted=false`, clientID).Scan(
+		&account.ID, &account.Name, &account.ClientID, &account.SecretHash, &account.Role, &account.OwnerID, &account.Created, &account.Updated)
+	if err != nil {
+		return nil, fmt.Errorf("serviceAccount - GetServiceAccountByClientID - Scan: %w", err)
+	}
+
+	return &account, nil
+}
+
+// GetServiceAccountByID get service account by id
+func (r *ServiceAccount) GetServiceAccountByID(ctx context.Context, id string) (*model.ServiceAccount, error) {
+	account := model.ServiceAccount{}
+	err := r.Pool.QueryRow(ctx, `select id, "name", client_id, secret_hash, "role", owner_id, created, updated
+									from service_accounts where id = $1 and deleted=false`, id).Scan(
+		&account.ID, &account.Name, &account.ClientID, &account.SecretHash, &account.Role, &account.OwnerID, &account.Created, &account.Updated)
+	if err != nil {
+		return nil, fmt.Errorf("serviceAccount - GetServiceAccountByID - Scan: %w", err)
+	}
+
+	return &account, nil
+}
+
+// DeleteServiceAccount delete service account
+func (r *ServiceAccount) DeleteServiceAccount(ctx context.Context, id string) error {
+	var idCheck string
+	err := r.Pool.QueryRow(ctx, "update service_accounts set deleted=true, updated=$1 where id=$2 and deleted=false returning id",
+		time.Now(), id).Scan(&idCheck)
+	if err != nil {
+		return fmt.Errorf("serviceAccount - DeleteServiceAccount - Scan: %w", err)
+	}
+
+	return nil
+}
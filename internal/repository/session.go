@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Session postgres entity
+type Session struct {
+	Pool *pgxpool.Pool
+}
+
+// NewSession creating new Session repository
+func NewSession(pool *pgxpool.Pool) *Session {
+	return &Session{Pool: pool}
+}
+
+// CreateSession create session
+func (r *Session) CreateSession(ctx context.Context, session *model.Session) error {
+	session.Created = time.Now()
+	session.LastUsed = session.Created
+	_, err := r.Pool.Exec(ctx,
+		`insert into sessions (id, user_id, device_name, user_agent, ip, remember, created, last_used) values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		session.ID, session.UserID, session.DeviceName, session.UserAgent, session.IP, session.Remember, session.Created, session.LastUsed)
+	if err != nil {
+		return fmt.Errorf("session - CreateSession - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// GetSessionByID get session by id
+func (r *Session) GetSessionByID(ctx context.Context, id string) (*model.Session, error) {
+	session := model.Session{}
+	err := r.Pool.QueryRow(ctx, `select id, user_id, device_name, user_agent, ip, revoked, remember, created, last_used
+									from sessions where id = $1`, id).Scan(
+		&session.ID, &session.UserID, &session.DeviceName, &session.UserAgent, &session.IP, &session.Revoked, &session.Remember, &session.Created, &session.LastUsed)
+	if err != nil {
+		return nil, fmt.Errorf("session - GetSessionByID - Scan: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetSessionsByUser get active sessions of user
+func (r *Session) GetSessionsByUser(ctx context.Context, userID string) ([]*model.Session, error) {
+	rows, err := r.Pool.Query(ctx, `select id, user_id, device_name, user_agent, ip, revoked, remember, created, last_used
+									from sessions where user_id = $1 and revoked=false order by last_used desc`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("session - GetSessionsByUser - Query: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*model.Session
+	for rows.Next() {
+		session := &model.Session{}
+		err = rows.Scan(&session.ID, &session.UserID, &session.DeviceName, &session.UserAgent, &session.IP, &session.Revoked,
+			&session.Remember, &session.Created, &session.LastUsed)
+		if err != nil {
+			return nil, fmt.Errorf("session - GetSessionsByUser - Scan: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("session - GetSessionsByUser - Err: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// TouchSession update last usage of session
+func (r *Session) TouchSession(ctx context.Context, session *model.Session) error {
+	session.LastUsed = time.Now()
+	var idCheck string
+	err := r.Pool.QueryRow(ctx, "update sessions set user_agent=$1, ip=$2, last_used=$3 where id=$4 and revoked=false returning id",
+		session.UserAgent, session.IP, session.LastUsed, session.ID).Scan(&idCheck)
+	if err != nil {
+		return fmt.Errorf("session - TouchSession - Scan: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeSession revoke session of user
+func (r *Session) RevokeSession(ctx context.Context, userID, id string) error {
+	var idCheck string
+	err := r.Pool.QueryRow(ctx, "update sessions set revoked=true where id=$1 and user_id=$2 and revoked=false returning id",
+		id, userID).Scan(&idCheck)
+	if err != nil {
+		return fmt.Errorf("session - RevokeSession - Scan: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions revoke all sessions of user except one
+func (r *Session) RevokeOtherSessions(ctx context.Context, userID, exceptID string) error {
+	_, err := r.Pool.Exec(ctx, "update sessions set revoked=true where user_id=$1 and id<>$2 and revoked=false",
+		userID, exceptID)
+	if err != nil {
+		return fmt.Errorf("session - RevokeOtherSessions - Exec: %w", err)
+	}
+
+	return nil
+}
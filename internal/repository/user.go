@@ -39,9 +39,10 @@ func (r *User) CreateUser(ctx context.Context, user *model.User) (*model.User, e
 // GetUserByLogin get user by login
 func (r *User) GetUserByLogin(ctx context.Context, login string) (*model.User, error) {
 	user := model.User{}
-	err := r.Pool.QueryRow(ctx, `select id, "name", age, "role", login, password, token, email
+	err := r.Pool.QueryRow(ctx, `select id, "name", age, "role", login, password, email, totp_secret, totp_enabled, token_version, password_changed
 									from users 	where login = $1 and deleted=false`, login).Scan(
-		&user.ID, &user.Name, &user.Age, &user.Role, &user.Login, &user.Password, &user.Token, &user.Email)
+		&user.ID, &user.Name, &user.Age, &user.Role, &user.Login, &user.Password, &user.Email, &user.TOTPSecret, &user.TOTPEnabled,
+		&user.TokenVersion, &user.PasswordChanged)
 	if err != nil {
 		return nil, fmt.Errorf("user - GetUserByLogin - Scan: %w", err)
 	}
@@ -52,9 +53,10 @@ func (r *User) GetUserByLogin(ctx context.Context, login string) (*model.User, e
 // GetUserByID get user by login
 func (r *User) GetUserByID(ctx context.Context, id string) (*model.User, error) {
 	user := model.User{}
-	err := r.Pool.QueryRow(ctx, `select id, "name", age, "role", login, password, token, email
+	err := r.Pool.QueryRow(ctx, `select id, "name", age, "role", login, password, email, totp_secret, totp_enabled, token_version, password_changed
 									from users where id = $1 and deleted=false`, id).Scan(
-		&user.ID, &user.Name, &user.Age, &user.Role, &user.Login, &user.Password, &user.Token, &user.Email)
+		&user.ID, &user.Name, &user.Age, &user.Role, &user.Login, &user.Password, &user.Email, &user.TOTPSecret, &user.TOTPEnabled,
+		&user.TokenVersion, &user.PasswordChanged)
 	if err != nil {
 		return nil, fmt.Errorf("user - GetUserByID - Scan: %w", err)
 	}
@@ -74,26 +76,84 @@ func (r *User) UpdateUser(ctx context.Context, id string, user *model.User) erro
 	return nil
 }
 
-// RefreshUser refresh user
-func (r *User) RefreshUser(ctx context.Context, id, token string) error {
+// DeleteUser delete user
+func (r *User) DeleteUser(ctx context.Context, id string) error {
 	var idCheck string
-	err := r.Pool.QueryRow(ctx, "update users set token=$1, updated=$2 where id=$3 and deleted=false returning id",
-		token, time.Now(), id).Scan(&idCheck)
+	err := r.Pool.QueryRow(ctx, "update users set Deleted=true, updated=$1 where id=$2 and deleted=false returning id",
+		time.Now(), id).Scan(&idCheck)
 	if err != nil {
-		return fmt.Errorf("user - RefreshUser - Exec: %w", err)
+		return fmt.Errorf("user - DeleteUser - Exec: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteUser delete user
-func (r *User) DeleteUser(ctx context.Context, id string) error {
+// SetTOTP set encrypted totp secret of user
+func (r *User) SetTOTP(ctx context.Context, id, secret string, enabled bool) error {
 	var idCheck string
-	err := r.Pool.QueryRow(ctx, "update users set Deleted=true, updated=$1 where id=$2 and deleted=false returning id",
-		time.Now(), id).Scan(&idCheck)
+	err := r.Pool.QueryRow(ctx, "update users set totp_secret=$1, totp_enabled=$2, updated=$3 where id=$4 and deleted=false returning id",
+		secret, enabled, time.Now(), id).Scan(&idCheck)
 	if err != nil {
-		return fmt.Errorf("user - DeleteUser - Exec: %w", err)
+		return fmt.Errorf("user - SetTOTP - Scan: %w", err)
 	}
 
 	return nil
 }
+
+// UseTOTPStep store time step of used totp code, false is returned if the same or newer step was already used
+func (r *User) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	tag, err := r.Pool.Exec(ctx, `update users set totp_last_step=$1
+									where id=$2 and deleted=false and (totp_last_step is null or totp_last_step<$1)`, step, id)
+	if err != nil {
+		return false, fmt.Errorf("user - UseTOTPStep - Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// SetPassword set password hash of user
+func (r *User) SetPassword(ctx context.Context, id, password string) error {
+	var idCheck string
+	err := r.Pool.QueryRow(ctx, `update users set "password"=$1, updated=$2, password_changed=$2 where id=$3 and deleted=false returning id`,
+		password, time.Now(), id).Scan(&idCheck)
+	if err != nil {
+		return fmt.Errorf("user - SetPassword - Scan: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePasswordHash replace password hash of user with new hash of the same password
+func (r *User) UpdatePasswordHash(ctx context.Context, id, password string) error {
+	var idCheck string
+	err := r.Pool.QueryRow(ctx, `update users set "password"=$1 where id=$2 and deleted=false returning id`,
+		password, id).Scan(&idCheck)
+	if err != nil {
+		return fmt.Errorf("user - UpdatePasswordHash - Scan: %w", err)
+	}
+
+	return nil
+}
+
+// GetTokenVersion get current token version of user
+func (r *User) GetTokenVersion(ctx context.Context, id string) (int, error) {
+	var version int
+	err := r.Pool.QueryRow(ctx, "select token_version from users where id = $1 and deleted=false", id).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("user - GetTokenVersion - Scan: %w", err)
+	}
+
+	return version, nil
+}
+
+// IncrementTokenVersion increment token version of user and return the new one
+func (r *User) IncrementTokenVersion(ctx context.Context, id string) (int, error) {
+	var version int
+	err := r.Pool.QueryRow(ctx, "update users set token_version=token_version+1, updated=$1 where id=$2 and deleted=false returning token_version",
+		time.Now(), id).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("user - IncrementTokenVersion - Scan: %w", err)
+	}
+
+	return version, nil
+}
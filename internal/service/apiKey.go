@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of public identifier part of api key
+const apiKeyPrefixBytes = 4
+
+// CreateAPIKey service issue api key with subset of scopes of user role, key without requested scopes
+// can only read profile, security scope must be requested explicitly and admin scope is never granted to keys,
+// key itself is returned only once
+func (u *User) CreateAPIKey(ctx context.Context, userID, name string, scopes []string, expires *time.Time) (key string, apiKey *model.APIKey, err error) {
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, userID); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateAPIKey - GetUserByID: %w", err)
+	}
+
+	allowed := model.RoleScopes(user.Role)
+	if len(scopes) == 0 {
+		scopes = []string{model.ScopeProfileRead}
+	}
+	for _, scope := range scopes {
+		if scope == model.ScopeAdmin || !model.HasScope(allowed, scope) {
+			return "", nil, fmt.Errorf("userService - CreateAPIKey - scope %q is not allowed", scope)
+		}
+	}
+	if expires != nil && !expires.After(time.Now()) {
+		return "", nil, fmt.Errorf("userService - CreateAPIKey - expiration is in the past")
+	}
+
+	prefix := make([]byte, apiKeyPrefixBytes)
+	if _, err = rand.Read(prefix); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateAPIKey - Read: %w", err)
+	}
+	var secret string
+	if secret, err = generateOpaqueToken(); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateAPIKey - generateOpaqueToken: %w", err)
+	}
+	apiKey = &model.APIKey{
+		ID:      uuid.New().String(),
+		UserID:  user.ID,
+		Name:    name,
+		Prefix:  model.APIKeyPrefix + hex.EncodeToString(prefix),
+		Scopes:  scopes,
+		Expires: expires,
+	}
+	key = apiKey.Prefix + "_" + secret
+	apiKey.KeyHash = hashToken(key)
+
+	if err = u.apiKeys.CreateAPIKey(ctx, apiKey); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateAPIKey - CreateAPIKey: %w", err)
+	}
+
+	return
+}
+
+// ListAPIKeys service not revoked api keys of user
+func (u *User) ListAPIKeys(ctx context.Context, userID string) (keys []*model.APIKey, err error) {
+	if keys, err = u.apiKeys.GetAPIKeysByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("userService - ListAPIKeys - GetAPIKeysByUser: %w", err)
+	}
+
+	return
+}
+
+// RevokeAPIKey service revoke api key of user
+func (u *User) RevokeAPIKey(ctx context.Context, userID, id string) (err error) {
+	if err = u.apiKeys.RevokeAPIKey(ctx, userID, id); err != nil {
+		return fmt.Errorf("userService - RevokeAPIKey - RevokeAPIKey: %w", err)
+	}
+
+	return
+}
+
+// AuthenticateAPIKey service resolving api key to the same claims as access token carries
+func (u *User) AuthenticateAPIKey(ctx context.Context, key string) (*CustomClaims, error) {
+	if !strings.HasPrefix(key, model.APIKeyPrefix) {
+		return nil, fmt.Errorf("userService - AuthenticateAPIKey - not an api key")
+	}
+
+	apiKey, err := u.apiKeys.GetAPIKeyByHash(ctx, hashToken(key))
+	if err != nil {
+		return nil, fmt.Errorf("userService - AuthenticateAPIKey - GetAPIKeyByHash: %w", err)
+	}
+	if apiKey.Revoked || (apiKey.Expires != nil && time.Now().After(*apiKey.Expires)) {
+		return nil, fmt.Errorf("userService - AuthenticateAPIKey - api key revoked or expired")
+	}
+
+	user, err := u.rps.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("userService - AuthenticateAPIKey - GetUserByID: %w", err)
+	}
+
+	// role of user could be changed after key was issued
+	allowed := model.RoleScopes(user.Role)
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		if scope != model.ScopeAdmin && model.HasScope(allowed, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if err = u.apiKeys.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		logrus.Error(fmt.Errorf("userService - AuthenticateAPIKey - TouchAPIKey: %w", err))
+	}
+
+	return &CustomClaims{
+		ID:     user.ID,
+		Role:   user.Role,
+		Type:   TokenTypeAPIKey,
+		Scopes: scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:      apiKey.ID,
+			Subject: user.ID,
+		},
+	}, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import "context"
+
+// Types of issued tokens, only access tokens are accepted by authorization,
+// claims resolved from api keys have their own type
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeAPIKey  = "api_key"
+)
+
+type claimsKey struct{}
+
+type clientIPKey struct{}
+
+// NewContextWithClaims context carrying claims of authenticated caller
+func NewContextWithClaims(ctx context.Context, claims *CustomClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext claims of authenticated caller
+func ClaimsFromContext(ctx context.Context) (*CustomClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*CustomClaims)
+	return claims, ok && claims != nil
+}
+
+// NewContextWithClientIP context carrying ip of client
+func NewContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext ip of client, empty if it is unknown
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
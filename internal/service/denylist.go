@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DenylistRepository repository interface for denied access tokens
+//
+//go:generate mockery --name=DenylistRepository --case=underscore --output=./mocks
+type DenylistRepository interface {
+	AddToDenylist(ctx context.Context, jti string, expires time.Time) error
+	IsDenylisted(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredDenylist(ctx context.Context) error
+}
+
+// How long absence of token in denylist is trusted without asking repository
+const denylistMissTTL = time.Second * 5
+
+// How long denied token is kept in cache
+const denylistHitTTL = time.Minute * 15
+
+type denylistEntry struct {
+	denied  bool
+	expires time.Time
+}
+
+// Denylist denied access tokens with in-process cache in front of repository
+type Denylist struct {
+	rps   DenylistRepository
+	mu    sync.RWMutex
+	cache map[string]denylistEntry
+}
+
+// NewDenylist new denylist
+func NewDenylist(rps DenylistRepository) *Denylist {
+	return &Denylist{rps: rps, cache: make(map[string]denylistEntry)}
+}
+
+// Add deny token until it expires
+func (d *Denylist) Add(ctx context.Context, jti string, expires time.Time) error {
+	if err := d.rps.AddToDenylist(ctx, jti, expires); err != nil {
+		return fmt.Errorf("denylist - Add - AddToDenylist: %w", err)
+	}
+
+	d.mu.Lock()
+	d.cache[jti] = denylistEntry{denied: true, expires: expires}
+	d.mu.Unlock()
+
+	return nil
+}
+
+// IsDenied check if token is denied
+func (d *Denylist) IsDenied(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+	d.mu.RLock()
+	entry, ok := d.cache[jti]
+	d.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry.denied, nil
+	}
+
+	denied, err := d.rps.IsDenylisted(ctx, jti)
+	if err != nil {
+		return false, fmt.Errorf("denylist - IsDenied - IsDenylisted: %w", err)
+	}
+
+	entry = denylistEntry{denied: denied, expires: now.Add(denylistMissTTL)}
+	if denied {
+		// denied entry is kept until cleanup removes expired tokens
+		entry.expires = now.Add(denylistHitTTL)
+	}
+	d.mu.Lock()
+	d.cache[jti] = entry
+	d.mu.Unlock()
+
+	return denied, nil
+}
+
+// Cleanup removing expired entries every interval until context is done
+func (d *Denylist) Cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.rps.DeleteExpiredDenylist(ctx); err != nil {
+				logrus.Error(fmt.Errorf("denylist - Cleanup - DeleteExpiredDenylist: %w", err))
+			}
+			d.evict()
+		}
+	}
+}
+
+func (d *Denylist) evict() {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for jti, entry := range d.cache {
+		if !now.Before(entry.expires) {
+			delete(d.cache, jti)
+		}
+	}
+}
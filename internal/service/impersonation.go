@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Expiration time of impersonation token, it is fixed and cannot be extended by refresh
+const impersonationExp = time.Minute * 10
+
+// ImpersonateUser service issue access token of user to admin, token carries admin as actor,
+// has no session and refresh token, every issue is recorded in audit log
+func (u *User) ImpersonateUser(ctx context.Context, actorID, userID, reason string) (accessToken string, expires time.Time, err error) {
+	if actorID == userID {
+		return "", time.Time{}, fmt.Errorf("userService - ImpersonateUser - cannot impersonate yourself")
+	}
+
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, userID); err != nil {
+		return "", time.Time{}, fmt.Errorf("userService - ImpersonateUser - GetUserByID: %w", err)
+	}
+	if user.Role == model.RoleAdmin {
+		return "", time.Time{}, fmt.Errorf("userService - ImpersonateUser - cannot impersonate admin")
+	}
+
+	expires = time.Now().Add(impersonationExp)
+	claims := &CustomClaims{
+		ID:               user.ID,
+		Role:             user.Role,
+		Type:             TokenTypeAccess,
+		Scopes:           model.RoleScopes(user.Role),
+		Actor:            &model.Actor{Subject: actorID},
+		Version:          user.TokenVersion,
+		RegisteredClaims: u.registeredClaims(user.ID, uuid.New().String(), u.audiences, expires),
+	}
+
+	event := &model.AuditEvent{
+		ID:        uuid.New().String(),
+		ActorID:   actorID,
+		SubjectID: user.ID,
+		Action:    model.AuditActionImpersonate,
+		Reason:    reason,
+		TokenID:   claims.RegisteredClaims.ID,
+		Expires:   &expires,
+	}
+	if err = u.audit.CreateAuditEvent(ctx, event); err != nil {
+		return "", time.Time{}, fmt.Errorf("userService - ImpersonateUser - CreateAuditEvent: %w", err)
+	}
+
+	if accessToken, err = u.signToken(claims); err != nil {
+		return "", time.Time{}, fmt.Errorf("userService - ImpersonateUser - signToken: %w", err)
+	}
+
+	return
+}
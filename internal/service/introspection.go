@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// IntrospectToken service authoritative state of access token,
+// token which fails any check is reported as inactive without details
+func (u *User) IntrospectToken(ctx context.Context, token string) (*model.Introspection, error) {
+	inactive := &model.Introspection{}
+
+	claims, err := u.tokenFormat.Verify(token)
+	if err != nil {
+		logrus.Debugf("userService - IntrospectToken - Verify: %v", err)
+		return inactive, nil
+	}
+	if claims.Type != TokenTypeAccess || !claims.VerifyIssuer(u.issuer, true) {
+		return inactive, nil
+	}
+
+	denied, err := u.denylist.IsDenied(ctx, claims.RegisteredClaims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("userService - IntrospectToken - IsDenied: %w", err)
+	}
+	if denied {
+		return inactive, nil
+	}
+
+	if claims.SessionID != "" {
+		var session *model.Session
+		if session, err = u.sessions.GetSessionByID(ctx, claims.SessionID); err != nil || session.Revoked {
+			return inactive, nil
+		}
+	}
+
+	introspection := &model.Introspection{
+		Active:   true,
+		JTI:      claims.RegisteredClaims.ID,
+		Issuer:   claims.Issuer,
+		Audience: claims.Audience,
+		Scopes:   claims.Scopes,
+		Actor:    claims.Actor,
+	}
+	if claims.ClientID != "" {
+		var account *model.ServiceAccount
+		if account, err = u.serviceAccounts.GetServiceAccountByID(ctx, claims.ID); err != nil {
+			logrus.Debugf("userService - IntrospectToken - GetServiceAccountByID: %v", err)
+			return inactive, nil
+		}
+		introspection.Subject, introspection.Role, introspection.ClientID = account.ID, account.Role, account.ClientID
+	} else {
+		var user *model.User
+		if user, err = u.rps.GetUserByID(ctx, claims.ID); err != nil {
+			logrus.Debugf("userService - IntrospectToken - GetUserByID: %v", err)
+			return inactive, nil
+		}
+		if claims.Version < user.TokenVersion {
+			return inactive, nil
+		}
+		introspection.Subject, introspection.Role = user.ID, user.Role
+	}
+	if claims.ExpiresAt != nil {
+		introspection.ExpiresAt = claims.ExpiresAt.Time
+	}
+	if claims.IssuedAt != nil {
+		introspection.IssuedAt = claims.IssuedAt.Time
+	}
+
+	return introspection, nil
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Signing algorithms supported by key set
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Size of generated rsa keys
+const rsaKeyBits = 2048
+
+// Extension of key files in keys directory
+const keyFileExt = ".pem"
+
+// SigningKey private key for signing tokens
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Created   time.Time
+}
+
+// KeySet rotating set of asymmetric signing keys persisted in directory shared by replicas,
+// only rotator writes new keys and every replica reloads directory to learn them
+type KeySet struct {
+	mu       sync.RWMutex
+	rotateMu sync.Mutex
+	keys     []*SigningKey
+	loaded   time.Time
+	alg      string
+	dir      string
+	jwksFile string
+	rotation time.Duration
+	retain   time.Duration
+	rotator  bool
+}
+
+// Minimal interval between reloads of keys directory caused by tokens with unknown kid
+const keyReloadMinInterval = time.Second
+
+// Header of pem block with creation time of key
+const keyCreatedHeader = "Created"
+
+// NewKeySet new key set, keys are loaded from dir which is required so keys survive restarts,
+// rotated keys stay valid for retain which must cover the longest token lifetime,
+// rotator generates new keys, other replicas only use keys it wrote
+func NewKeySet(alg, dir, jwksFile string, rotation, retain time.Duration, rotator bool) (*KeySet, error) {
+	if alg != AlgorithmRS256 && alg != AlgorithmEdDSA {
+		return nil, fmt.Errorf("keySet - NewKeySet - unsupported algorithm %q", alg)
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("keySet - NewKeySet - keys directory is required")
+	}
+	k := &KeySet{
+		alg:      alg,
+		dir:      dir,
+		jwksFile: jwksFile,
+		rotation: rotation,
+		retain:   retain,
+		rotator:  rotator,
+	}
+
+	if rotator {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("keySet - NewKeySet - MkdirAll: %w", err)
+		}
+	}
+	if err := k.Reload(); err != nil {
+		return nil, fmt.Errorf("keySet - NewKeySet - Reload: %w", err)
+	}
+	if _, err := k.Current(); err != nil {
+		return nil, fmt.Errorf("keySet - NewKeySet - Current: %w", err)
+	}
+	if rotator {
+		if err := k.export(); err != nil {
+			return nil, fmt.Errorf("keySet - NewKeySet - export: %w", err)
+		}
+	}
+
+	return k, nil
+}
+
+// Current key for signing, rotator rotates key when it is older than rotation period,
+// other replicas keep signing with the newest key until they load the rotated one
+func (k *KeySet) Current() (*SigningKey, error) {
+	k.mu.RLock()
+	current := k.current()
+	k.mu.RUnlock()
+	if k.fresh(current) {
+		return current, nil
+	}
+	if !k.rotator {
+		if current == nil || current.Algorithm != k.alg {
+			return nil, fmt.Errorf("keySet - Current - no %s key written by rotator in %s", k.alg, k.dir)
+		}
+		return current, nil
+	}
+
+	k.rotateMu.Lock()
+	defer k.rotateMu.Unlock()
+	k.mu.RLock()
+	current = k.current()
+	k.mu.RUnlock()
+	if k.fresh(current) {
+		return current, nil
+	}
+	return k.rotate()
+}
+
+// Rotate generate new current key, previous keys stay valid until their tokens expire
+func (k *KeySet) Rotate() (*SigningKey, error) {
+	if !k.rotator {
+		return nil, fmt.Errorf("keySet - Rotate - replica is not a rotator")
+	}
+	k.rotateMu.Lock()
+	defer k.rotateMu.Unlock()
+
+	return k.rotate()
+}
+
+// Reload loading keys from directory, keys which are already known are kept
+func (k *KeySet) Reload() error {
+	loaded, err := k.load()
+	if err != nil {
+		return fmt.Errorf("keySet - Reload - load: %w", err)
+	}
+
+	k.mu.Lock()
+	known := make(map[string]bool, len(k.keys))
+	for _, key := range k.keys {
+		known[key.ID] = true
+	}
+	for _, key := range loaded {
+		if !known[key.ID] {
+			k.keys = append(k.keys, key)
+		}
+	}
+	sort.Slice(k.keys, func(i, j int) bool {
+		return k.keys[i].Created.Before(k.keys[j].Created)
+	})
+	expired := k.prune()
+	k.loaded = time.Now()
+	k.mu.Unlock()
+
+	k.remove(expired)
+
+	return nil
+}
+
+// Watch reloading keys from directory every interval until context is done
+func (k *KeySet) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(); err != nil {
+				logrus.Error(fmt.Errorf("keySet - Watch - Reload: %w", err))
+			}
+		}
+	}
+}
+
+// KeyFunc resolving public key by kid header for token verification
+func (k *KeySet) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := k.PublicKey(kid, token.Method.Alg())
+	if err != nil {
+		return nil, fmt.Errorf("keySet - KeyFunc - PublicKey: %w", err)
+	}
+
+	return key, nil
+}
+
+// PublicKey public key with kid which is still valid for verification of tokens signed with alg,
+// unknown kid causes reload of keys directory as it could be just written by rotator
+func (k *KeySet) PublicKey(kid, alg string) (crypto.PublicKey, error) {
+	key, found, err := k.publicKey(kid, alg)
+	if found {
+		return key, err
+	}
+
+	k.mu.RLock()
+	stale := time.Since(k.loaded) >= keyReloadMinInterval
+	k.mu.RUnlock()
+	if stale {
+		if err = k.Reload(); err != nil {
+			return nil, fmt.Errorf("keySet - PublicKey - Reload: %w", err)
+		}
+		if key, found, err = k.publicKey(kid, alg); found {
+			return key, err
+		}
+	}
+
+	return nil, fmt.Errorf("keySet - PublicKey - unknown key %q", kid)
+}
+
+func (k *KeySet) publicKey(kid, alg string) (crypto.PublicKey, bool, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := time.Now()
+	for i, key := range k.keys {
+		if key.ID != kid {
+			continue
+		}
+		if alg != key.Algorithm {
+			return nil, true, fmt.Errorf("keySet - PublicKey - unexpected signing method %q", alg)
+		}
+		if !k.valid(i, now) {
+			return nil, true, fmt.Errorf("keySet - PublicKey - key %q expired", kid)
+		}
+		return key.Private.Public(), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// JWKS public keys which are still valid for verification
+func (k *KeySet) JWKS() *model.JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	jwks := &model.JWKS{Keys: make([]*model.JWK, 0, len(k.keys))}
+	now := time.Now()
+	for i, key := range k.keys {
+		if k.valid(i, now) {
+			jwks.Keys = append(jwks.Keys, toJWK(key))
+		}
+	}
+
+	return jwks
+}
+
+func (k *KeySet) current() *SigningKey {
+	if len(k.keys) == 0 {
+		return nil
+	}
+	return k.keys[len(k.keys)-1]
+}
+
+// fresh key can still be used for signing
+func (k *KeySet) fresh(key *SigningKey) bool {
+	return key != nil && key.Algorithm == k.alg && time.Since(key.Created) < k.rotation
+}
+
+// valid key is current or its successor was created less than retain ago
+func (k *KeySet) valid(i int, now time.Time) bool {
+	if i == len(k.keys)-1 {
+		return true
+	}
+	return now.Before(k.keys[i+1].Created.Add(k.retain))
+}
+
+// rotate generating and storing new key, verification is blocked only while key is appended
+func (k *KeySet) rotate() (*SigningKey, error) {
+	key, err := k.generate()
+	if err != nil {
+		return nil, fmt.Errorf("keySet - rotate - generate: %w", err)
+	}
+	if err = k.store(key); err != nil {
+		return nil, fmt.Errorf("keySet - rotate - store: %w", err)
+	}
+
+	k.mu.Lock()
+	k.keys = append(k.keys, key)
+	expired := k.prune()
+	err = k.export()
+	k.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("keySet - rotate - export: %w", err)
+	}
+
+	k.remove(expired)
+
+	return key, nil
+}
+
+// prune dropping keys past their verification window and returning them
+func (k *KeySet) prune() []*SigningKey {
+	now := time.Now()
+	var expired []*SigningKey
+	keys := make([]*SigningKey, 0, len(k.keys))
+	for i, key := range k.keys {
+		if k.valid(i, now) {
+			keys = append(keys, key)
+		} else {
+			expired = append(expired, key)
+		}
+	}
+	k.keys = keys
+
+	return expired
+}
+
+// remove deleting files of expired keys, only rotator writes to keys directory,
+// so private keys do not stay on disk and replicas stop loading them
+func (k *KeySet) remove(expired []*SigningKey) {
+	if !k.rotator {
+		return
+	}
+	for _, key := range expired {
+		err := os.Remove(filepath.Join(k.dir, key.ID+keyFileExt))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logrus.Error(fmt.Errorf("keySet - remove - Remove: %w", err))
+		}
+	}
+}
+
+func (k *KeySet) generate() (*SigningKey, error) {
+	key := &SigningKey{
+		ID:        uuid.New().String(),
+		Algorithm: k.alg,
+		Created:   time.Now(),
+	}
+
+	var err error
+	switch k.alg {
+	case AlgorithmRS256:
+		key.Private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, key.Private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keySet - generate - GenerateKey: %w", err)
+	}
+
+	return key, nil
+}
+
+func (k *KeySet) load() ([]*SigningKey, error) {
+	entries, err := os.ReadDir(k.dir)
+	if err != nil {
+		return nil, fmt.Errorf("keySet - load - ReadDir: %w", err)
+	}
+
+	keys := make([]*SigningKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+		var key *SigningKey
+		if key, err = k.read(entry); err != nil {
+			// expired key removed by rotator after directory was listed
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("keySet - load - read: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (k *KeySet) read(entry os.DirEntry) (*SigningKey, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return nil, fmt.Errorf("keySet - read - Info: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(k.dir, entry.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("keySet - read - ReadFile: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("keySet - read - Decode: no pem data in %s", entry.Name())
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("keySet - read - ParsePKCS8PrivateKey: %w", err)
+	}
+
+	// keys written before creation header was introduced fall back to modification time
+	key := &SigningKey{
+		ID:      strings.TrimSuffix(entry.Name(), keyFileExt),
+		Created: info.ModTime(),
+	}
+	if created, ok := block.Headers[keyCreatedHeader]; ok {
+		if key.Created, err = time.Parse(time.RFC3339Nano, created); err != nil {
+			return nil, fmt.Errorf("keySet - read - Parse: %w", err)
+		}
+	}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Private = AlgorithmRS256, private
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Private = AlgorithmEdDSA, private
+	default:
+		return nil, fmt.Errorf("keySet - read - unsupported key type %T", parsed)
+	}
+
+	return key, nil
+}
+
+func (k *KeySet) store(key *SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return fmt.Errorf("keySet - store - MarshalPKCS8PrivateKey: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{keyCreatedHeader: key.Created.Format(time.RFC3339Nano)},
+		Bytes:   der,
+	})
+	// other replicas must never read partially written key
+	path := filepath.Join(k.dir, key.ID+keyFileExt)
+	if err = os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("keySet - store - WriteFile: %w", err)
+	}
+	if err = os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("keySet - store - Rename: %w", err)
+	}
+
+	return nil
+}
+
+// export writing public keys to jwks file, so they can be served without the service
+func (k *KeySet) export() error {
+	if k.jwksFile == "" {
+		return nil
+	}
+
+	jwks := &model.JWKS{Keys: make([]*model.JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwks.Keys = append(jwks.Keys, toJWK(key))
+	}
+	data, err := json.MarshalIndent(jwks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("keySet - export - MarshalIndent: %w", err)
+	}
+	tmp := k.jwksFile + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil { //nolint:gosec // file contains only public keys
+		return fmt.Errorf("keySet - export - WriteFile: %w", err)
+	}
+	if err = os.Rename(tmp, k.jwksFile); err != nil {
+		return fmt.Errorf("keySet - export - Rename: %w", err)
+	}
+
+	return nil
+}
+
+func toJWK(key *SigningKey) *model.JWK {
+	jwk := &model.JWK{
+		Kid: key.ID,
+		Use: "sig",
+		Alg: key.Algorithm,
+	}
+	switch public := key.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeySetSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	rotator, err := NewKeySet(AlgorithmEdDSA, dir, "", time.Hour, time.Hour, true)
+	if err != nil {
+		t.Fatalf("NewKeySet rotator: %v", err)
+	}
+	replica, err := NewKeySet(AlgorithmEdDSA, dir, "", time.Hour, time.Hour, false)
+	if err != nil {
+		t.Fatalf("NewKeySet replica: %v", err)
+	}
+
+	first, err := rotator.Current()
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	replicaCurrent, err := replica.Current()
+	if err != nil {
+		t.Fatalf("replica Current: %v", err)
+	}
+	if replicaCurrent.ID != first.ID || !replicaCurrent.Created.Equal(first.Created) {
+		t.Fatalf("replica signs with %s created %s, want %s created %s",
+			replicaCurrent.ID, replicaCurrent.Created, first.ID, first.Created)
+	}
+
+	second, err := rotator.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if err = replica.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	for _, kid := range []string{first.ID, second.ID} {
+		if _, err = replica.PublicKey(kid, AlgorithmEdDSA); err != nil {
+			t.Fatalf("replica PublicKey(%s): %v", kid, err)
+		}
+	}
+	if _, err = replica.Rotate(); err == nil {
+		t.Fatal("replica rotated key")
+	}
+}
+
+func TestKeySetRequiresKeys(t *testing.T) {
+	if _, err := NewKeySet(AlgorithmEdDSA, "", "", time.Hour, time.Hour, true); err == nil {
+		t.Fatal("key set without directory is created")
+	}
+	if _, err := NewKeySet(AlgorithmEdDSA, t.TempDir(), "", time.Hour, time.Hour, false); err == nil {
+		t.Fatal("replica without keys of rotator is created")
+	}
+}
+
+func TestKeySetRemovesExpiredKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	retain := 50 * time.Millisecond
+	rotator, err := NewKeySet(AlgorithmEdDSA, dir, "", time.Hour, retain, true)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	first, err := rotator.Current()
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	if _, err = rotator.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, first.ID+keyFileExt)); err != nil {
+		t.Fatalf("key file removed before retain passed: %v", err)
+	}
+
+	time.Sleep(retain * 2)
+	if err = rotator.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, first.ID+keyFileExt)); !os.IsNotExist(err) {
+		t.Fatalf("expired key file is kept: %v", err)
+	}
+	if _, err = rotator.PublicKey(first.ID, AlgorithmEdDSA); err == nil {
+		t.Fatal("expired key is still used for verification")
+	}
+}
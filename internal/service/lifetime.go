@@ -0,0 +1,95 @@
+package service
+
+import (
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+)
+
+// Lifetime lifetimes of tokens issued to one role, zero values mean no limit for SessionMax
+// and fallback to default lifetimes for role overrides
+type Lifetime struct {
+	Access  time.Duration
+	Refresh time.Duration
+	// RememberMe lifetime of refresh tokens of sessions started with remember me
+	RememberMe time.Duration
+	// SessionMax absolute lifetime of session, it is not extended by refresh
+	SessionMax time.Duration
+}
+
+// Lifetimes default token lifetimes with per role overrides
+type Lifetimes struct {
+	Default Lifetime
+	Roles   map[string]Lifetime
+}
+
+// ForRole lifetimes of role, fields missing in role override are taken from default
+func (l Lifetimes) ForRole(role string) Lifetime {
+	lifetime := l.Default
+	override, ok := l.Roles[role]
+	if !ok {
+		return lifetime
+	}
+
+	if override.Access > 0 {
+		lifetime.Access = override.Access
+	}
+	if override.Refresh > 0 {
+		lifetime.Refresh = override.Refresh
+	}
+	if override.RememberMe > 0 {
+		lifetime.RememberMe = override.RememberMe
+	}
+	if override.SessionMax > 0 {
+		lifetime.SessionMax = override.SessionMax
+	}
+
+	return lifetime
+}
+
+// Longest the longest lifetime of any issued token
+func (l Lifetimes) Longest() time.Duration {
+	longest := time.Duration(0)
+	check := func(lifetime Lifetime) {
+		for _, d := range []time.Duration{lifetime.Access, lifetime.Refresh, lifetime.RememberMe} {
+			if d > longest {
+				longest = d
+			}
+		}
+	}
+
+	check(l.Default)
+	for role := range l.Roles {
+		check(l.ForRole(role))
+	}
+
+	return longest
+}
+
+// refreshExpiration expiration of refresh token issued now for session,
+// it never exceeds absolute session lifetime
+func (l Lifetime) refreshExpiration(session *model.Session, now time.Time) time.Time {
+	ttl := l.Refresh
+	if session.Remember && l.RememberMe > ttl {
+		ttl = l.RememberMe
+	}
+
+	return l.capToSession(session, now.Add(ttl))
+}
+
+// capToSession expiration limited by absolute session lifetime
+func (l Lifetime) capToSession(session *model.Session, expires time.Time) time.Time {
+	if l.SessionMax <= 0 {
+		return expires
+	}
+	if limit := session.Created.Add(l.SessionMax); limit.Before(expires) {
+		return limit
+	}
+
+	return expires
+}
+
+// sessionExpired session is older than absolute session lifetime
+func (l Lifetime) sessionExpired(session *model.Session, now time.Time) bool {
+	return l.SessionMax > 0 && now.After(session.Created.Add(l.SessionMax))
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LoginAttemptRepository repository interface for failed login attempts
+//
+//go:generate mockery --name=LoginAttemptRepository --case=underscore --output=./mocks
+type LoginAttemptRepository interface {
+	GetLoginLock(ctx context.Context, kind, key string) (time.Time, error)
+	AddLoginFailure(ctx context.Context, kind, key string, window time.Duration) (int, error)
+	LockLogin(ctx context.Context, kind, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, kind, key string) error
+	DeleteExpiredLoginAttempts(ctx context.Context, before time.Time) error
+}
+
+// ErrInvalidCredentials login is unknown or password is invalid, the two are not distinguished
+var ErrInvalidCredentials = errors.New("invalid login or password")
+
+// LoginLockedError too many failed attempts for login or client, it is returned for unknown logins too
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+// Error message of lockout
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// LockoutPolicy thresholds of failed attempts after which login or client ip is locked for base lock,
+// every further failure doubles the lock up to max lock, zero threshold disables lockout for key kind
+type LockoutPolicy struct {
+	LoginThreshold int
+	IPThreshold    int
+	BaseLock       time.Duration
+	MaxLock        time.Duration
+	// Window failures older than window are forgotten
+	Window time.Duration
+}
+
+// Lockout failed login attempts counted per login and per client ip,
+// failed second factor codes are counted per user and per client ip
+type Lockout struct {
+	rps    LoginAttemptRepository
+	policy LockoutPolicy
+}
+
+// NewLockout new lockout
+func NewLockout(rps LoginAttemptRepository, policy LockoutPolicy) *Lockout {
+	return &Lockout{rps: rps, policy: policy}
+}
+
+// Check return LoginLockedError if login or client ip is locked
+func (l *Lockout) Check(ctx context.Context, login, ip string) error {
+	if err := l.check(ctx, l.keys(model.LoginAttemptLogin, login, ip)); err != nil {
+		return fmt.Errorf("lockout - Check - check: %w", err)
+	}
+
+	return nil
+}
+
+// Fail count failed attempt of login and client ip, locking them after threshold
+func (l *Lockout) Fail(ctx context.Context, login, ip string) error {
+	if err := l.fail(ctx, l.keys(model.LoginAttemptLogin, login, ip)); err != nil {
+		return fmt.Errorf("lockout - Fail - fail: %w", err)
+	}
+
+	return nil
+}
+
+// Reset forget failed attempts of login after successful login, failures of client ip are kept until window passes,
+// otherwise attacker could guess other logins and clear the ip counter by logging into own account
+func (l *Lockout) Reset(ctx context.Context, login string) error {
+	if err := l.reset(ctx, l.keys(model.LoginAttemptLogin, login, "")); err != nil {
+		return fmt.Errorf("lockout - Reset - reset: %w", err)
+	}
+
+	return nil
+}
+
+// CheckSecondFactor return LoginLockedError if second factor of user or client ip is locked
+func (l *Lockout) CheckSecondFactor(ctx context.Context, userID, ip string) error {
+	if err := l.check(ctx, l.keys(model.LoginAttemptSecondFactor, userID, ip)); err != nil {
+		return fmt.Errorf("lockout - CheckSecondFactor - check: %w", err)
+	}
+
+	return nil
+}
+
+// FailSecondFactor count failed second factor code of user and client ip, counted across all challenges of user
+func (l *Lockout) FailSecondFactor(ctx context.Context, userID, ip string) error {
+	if err := l.fail(ctx, l.keys(model.LoginAttemptSecondFactor, userID, ip)); err != nil {
+		return fmt.Errorf("lockout - FailSecondFactor - fail: %w", err)
+	}
+
+	return nil
+}
+
+// ResetSecondFactor forget failed second factor codes of user after successful verification
+func (l *Lockout) ResetSecondFactor(ctx context.Context, userID string) error {
+	if err := l.reset(ctx, l.keys(model.LoginAttemptSecondFactor, userID, "")); err != nil {
+		return fmt.Errorf("lockout - ResetSecondFactor - reset: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Lockout) check(ctx context.Context, keys []lockoutKey) error {
+	var until time.Time
+	for _, k := range keys {
+		locked, err := l.rps.GetLoginLock(ctx, k.kind, k.key)
+		if err != nil {
+			return fmt.Errorf("GetLoginLock: %w", err)
+		}
+		if locked.After(until) {
+			until = locked
+		}
+	}
+	if retryAfter := time.Until(until); retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (l *Lockout) fail(ctx context.Context, keys []lockoutKey) error {
+	for _, k := range keys {
+		failures, err := l.rps.AddLoginFailure(ctx, k.kind, k.key, l.policy.Window)
+		if err != nil {
+			return fmt.Errorf("AddLoginFailure: %w", err)
+		}
+		if failures < k.threshold {
+			continue
+		}
+		if err = l.rps.LockLogin(ctx, k.kind, k.key, time.Now().Add(l.lockDuration(failures-k.threshold))); err != nil {
+			return fmt.Errorf("LockLogin: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *Lockout) reset(ctx context.Context, keys []lockoutKey) error {
+	for _, k := range keys {
+		if err := l.rps.ResetLoginAttempts(ctx, k.kind, k.key); err != nil {
+			return fmt.Errorf("ResetLoginAttempts: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Unlock forget failed attempts and lock of login
+func (l *Lockout) Unlock(ctx context.Context, login string) error {
+	if err := l.rps.ResetLoginAttempts(ctx, model.LoginAttemptLogin, login); err != nil {
+		return fmt.Errorf("lockout - Unlock - ResetLoginAttempts: %w", err)
+	}
+
+	return nil
+}
+
+// Cleanup removing forgotten attempts every interval until context is done
+func (l *Lockout) Cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.rps.DeleteExpiredLoginAttempts(ctx, time.Now().Add(-l.policy.Window)); err != nil {
+				logrus.Error(fmt.Errorf("lockout - Cleanup - DeleteExpiredLoginAttempts: %w", err))
+			}
+		}
+	}
+}
+
+type lockoutKey struct {
+	kind      string
+	key       string
+	threshold int
+}
+
+// keys counted keys of attempt of account kind and client ip, second factor shares threshold with login,
+// kinds with disabled threshold and empty keys are skipped
+func (l *Lockout) keys(kind, account, ip string) []lockoutKey {
+	var keys []lockoutKey
+	if l.policy.LoginThreshold > 0 && account != "" {
+		keys = append(keys, lockoutKey{kind: kind, key: account, threshold: l.policy.LoginThreshold})
+	}
+	if l.policy.IPThreshold > 0 && ip != "" {
+		keys = append(keys, lockoutKey{kind: model.LoginAttemptIP, key: ip, threshold: l.policy.IPThreshold})
+	}
+	return keys
+}
+
+// lockDuration base lock doubled for every failure over threshold, capped by max lock
+func (l *Lockout) lockDuration(over int) time.Duration {
+	lock := l.policy.BaseLock
+	for i := 0; i < over && lock < l.policy.MaxLock; i++ {
+		lock *= 2
+	}
+	if l.policy.MaxLock > 0 && lock > l.policy.MaxLock {
+		lock = l.policy.MaxLock
+	}
+	return lock
+}
+
+// UnlockUser service forget failed login and second factor attempts and lock of user, unlock is recorded in audit log
+func (u *User) UnlockUser(ctx context.Context, actorID, userID string) error {
+	user, err := u.rps.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("userService - UnlockUser - GetUserByID: %w", err)
+	}
+	if err = u.lockout.Unlock(ctx, user.Login); err != nil {
+		return fmt.Errorf("userService - UnlockUser - Unlock: %w", err)
+	}
+	if err = u.lockout.ResetSecondFactor(ctx, user.ID); err != nil {
+		return fmt.Errorf("userService - UnlockUser - ResetSecondFactor: %w", err)
+	}
+
+	event := &model.AuditEvent{
+		ID:        uuid.New().String(),
+		ActorID:   actorID,
+		SubjectID: user.ID,
+		Action:    model.AuditActionUnlock,
+	}
+	if err = u.audit.CreateAuditEvent(ctx, event); err != nil {
+		return fmt.Errorf("userService - UnlockUser - CreateAuditEvent: %w", err)
+	}
+
+	return nil
+}
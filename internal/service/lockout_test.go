@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+)
+
+type fakeAttempt struct {
+	failures int
+	locked   time.Time
+}
+
+type fakeLoginAttempts struct {
+	LoginAttemptRepository
+	attempts map[string]*fakeAttempt
+}
+
+func newFakeLoginAttempts() *fakeLoginAttempts {
+	return &fakeLoginAttempts{attempts: map[string]*fakeAttempt{}}
+}
+
+func (f *fakeLoginAttempts) attempt(kind, key string) *fakeAttempt {
+	a, ok := f.attempts[kind+"|"+key]
+	if !ok {
+		a = &fakeAttempt{}
+		f.attempts[kind+"|"+key] = a
+	}
+	return a
+}
+
+func (f *fakeLoginAttempts) GetLoginLock(_ context.Context, kind, key string) (time.Time, error) {
+	return f.attempt(kind, key).locked, nil
+}
+
+func (f *fakeLoginAttempts) AddLoginFailure(_ context.Context, kind, key string, _ time.Duration) (int, error) {
+	a := f.attempt(kind, key)
+	a.failures++
+	return a.failures, nil
+}
+
+func (f *fakeLoginAttempts) LockLogin(_ context.Context, kind, key string, until time.Time) error {
+	f.attempt(kind, key).locked = until
+	return nil
+}
+
+func (f *fakeLoginAttempts) ResetLoginAttempts(_ context.Context, kind, key string) error {
+	delete(f.attempts, kind+"|"+key)
+	return nil
+}
+
+func TestLockoutKeepsIPFailuresAfterSuccess(t *testing.T) {
+	attempts := newFakeLoginAttempts()
+	lockout := NewLockout(attempts, LockoutPolicy{
+		LoginThreshold: 100,
+		IPThreshold:    5,
+		BaseLock:       time.Minute,
+		MaxLock:        time.Hour,
+		Window:         time.Hour,
+	})
+	ctx := context.Background()
+	const ip = "198.51.100.1"
+
+	// guessing victim password and logging into own account after every two guesses
+	guesses := 0
+	for ; guesses < 20; guesses++ {
+		if lockout.Check(ctx, "victim", ip) != nil {
+			break
+		}
+		if err := lockout.Fail(ctx, "victim", ip); err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+		if guesses%2 == 1 {
+			if err := lockout.Reset(ctx, "attacker"); err != nil {
+				t.Fatalf("Reset: %v", err)
+			}
+		}
+	}
+	if guesses != 5 {
+		t.Fatalf("ip is locked after %d guesses, want 5", guesses)
+	}
+
+	var locked *LoginLockedError
+	if err := lockout.Check(ctx, "attacker", ip); !errors.As(err, &locked) {
+		t.Fatalf("Check() = %v, want ip locked for own account too", err)
+	}
+	if got := attempts.attempt(model.LoginAttemptLogin, "victim").failures; got != 5 {
+		t.Fatalf("victim failures = %d, want 5", got)
+	}
+}
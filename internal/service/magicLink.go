@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Expiration time of magic link
+const magicLinkExp = time.Minute * 15
+
+// RequestMagicLink service send single use login link to email of user,
+// unknown logins are not reported and link is created and sent in background,
+// so accounts cannot be enumerated by response or its time
+func (u *User) RequestMagicLink(ctx context.Context, login string) error {
+	user, err := u.rps.GetUserByLogin(ctx, login)
+	if err != nil {
+		logrus.Debugf("userService - RequestMagicLink - GetUserByLogin: %v", err)
+		return nil
+	}
+
+	u.inBackground("RequestMagicLink", func(ctx context.Context) error {
+		token, err := u.createOneTimeToken(ctx, user, model.PurposeMagicLink, magicLinkExp)
+		if err != nil {
+			return fmt.Errorf("createOneTimeToken: %w", err)
+		}
+
+		notification := &model.Notification{
+			To:      user.Email,
+			Subject: "Your login link",
+			Body: fmt.Sprintf("Hello, %s!\n\nFollow the link to log in, it is valid for %s:\n%s\n\nIf you did not request it, ignore this message.",
+				user.Name, magicLinkExp, linkWithToken(u.magicLinkURL, token)),
+		}
+		if err = u.notifier.Send(ctx, notification); err != nil {
+			return fmt.Errorf("Send: %w", err)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+// ConsumeMagicLink service login with token from magic link, user with expired password has to reset it
+func (u *User) ConsumeMagicLink(ctx context.Context, token string, session *model.Session) (accessToken, refreshToken, challengeToken string, err error) {
+	var stored *model.OneTimeToken
+	if stored, err = u.oneTimeTokens.GetOneTimeToken(ctx, model.PurposeMagicLink, hashToken(token)); err != nil {
+		return "", "", "", fmt.Errorf("userService - ConsumeMagicLink - GetOneTimeToken: %w", err)
+	}
+
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, stored.UserID); err != nil {
+		return "", "", "", fmt.Errorf("userService - ConsumeMagicLink - GetUserByID: %w", err)
+	}
+	if u.policy.Expired(user.PasswordChanged) {
+		return "", "", "", fmt.Errorf("userService - ConsumeMagicLink: %w", ErrPasswordExpired)
+	}
+	if _, err = u.oneTimeTokens.UseOneTimeToken(ctx, model.PurposeMagicLink, stored.TokenHash); err != nil {
+		return "", "", "", fmt.Errorf("userService - ConsumeMagicLink - UseOneTimeToken: %w", err)
+	}
+
+	accessToken, refreshToken, challengeToken, err = u.startSession(ctx, user, session)
+	if err != nil {
+		return "", "", "", fmt.Errorf("userService - ConsumeMagicLink - startSession: %w", err)
+	}
+
+	return
+}
+
+// Timeout of work done in background after response
+const backgroundTimeout = time.Minute
+
+// inBackground running work after response is sent, so response time does not depend on it,
+// failure is only logged
+func (u *User) inBackground(method string, work func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		if err := work(ctx); err != nil {
+			logrus.Error(fmt.Errorf("userService - %s - %w", method, err))
+		}
+	}()
+}
+
+// createOneTimeToken storing hash of new single use token
+func (u *User) createOneTimeToken(ctx context.Context, user *model.User, purpose string, exp time.Duration) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("userService - createOneTimeToken - generateOpaqueToken: %w", err)
+	}
+
+	stored := &model.OneTimeToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Expires:   time.Now().Add(exp),
+	}
+	if err = u.oneTimeTokens.CreateOneTimeToken(ctx, stored); err != nil {
+		return "", fmt.Errorf("userService - createOneTimeToken - CreateOneTimeToken: %w", err)
+	}
+
+	return token, nil
+}
+
+// linkWithToken adding token as query parameter of link
+func linkWithToken(link, token string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+
+	query := parsed.Query()
+	query.Set("token", token)
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Header of PASETO v4 tokens with public key signatures
+const pasetoHeader = "v4.public."
+
+// Registered PASETO claims which are ISO 8601 strings instead of numeric dates
+var pasetoTimeClaims = []string{"exp", "nbf", "iat"} //nolint:gochecknoglobals // immutable list
+
+// PASETO v4.public tokens signed with Ed25519 keys, footer carries kid of signing key
+type PASETO struct {
+	keys *KeySet
+}
+
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// NewPASETO new paseto v4.public token format
+func NewPASETO(keys *KeySet) *PASETO {
+	return &PASETO{keys: keys}
+}
+
+// Issue signing claims with current key
+func (t *PASETO) Issue(claims *CustomClaims) (string, error) {
+	key, err := t.keys.Current()
+	if err != nil {
+		return "", fmt.Errorf("paseto - Issue - Current: %w", err)
+	}
+	private, ok := key.Private.(ed25519.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("paseto - Issue - key %q is not an Ed25519 key", key.ID)
+	}
+
+	message, err := encodePASETOClaims(claims)
+	if err != nil {
+		return "", fmt.Errorf("paseto - Issue - encodePASETOClaims: %w", err)
+	}
+	footer, err := json.Marshal(pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", fmt.Errorf("paseto - Issue - Marshal: %w", err)
+	}
+
+	return signPASETO(private, message, footer), nil
+}
+
+// Verify checking signature with key from footer and validity of claims
+func (t *PASETO) Verify(token string) (*CustomClaims, error) {
+	message, signature, footer, err := decodePASETO(token)
+	if err != nil {
+		return nil, fmt.Errorf("paseto - Verify - decodePASETO: %w", err)
+	}
+	var decodedFooter pasetoFooter
+	if err = json.Unmarshal(footer, &decodedFooter); err != nil {
+		return nil, fmt.Errorf("paseto - Verify - Unmarshal: %w", err)
+	}
+
+	key, err := t.keys.PublicKey(decodedFooter.KeyID, AlgorithmEdDSA)
+	if err != nil {
+		return nil, fmt.Errorf("paseto - Verify - PublicKey: %w", err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("paseto - Verify - key %q is not an Ed25519 key", decodedFooter.KeyID)
+	}
+
+	if !verifyPASETO(public, message, signature, footer) {
+		return nil, fmt.Errorf("paseto - Verify - signature is invalid")
+	}
+
+	claims, err := decodePASETOClaims(message)
+	if err != nil {
+		return nil, fmt.Errorf("paseto - Verify - decodePASETOClaims: %w", err)
+	}
+	if err = claims.Valid(); err != nil {
+		return nil, fmt.Errorf("paseto - Verify - Valid: %w", err)
+	}
+
+	return claims, nil
+}
+
+// signPASETO v4.public token of message and footer, empty footer is omitted
+func signPASETO(private ed25519.PrivateKey, message, footer []byte) string {
+	signature := ed25519.Sign(private, pae([]byte(pasetoHeader), message, footer, nil))
+	token := pasetoHeader + base64.RawURLEncoding.EncodeToString(append(append([]byte{}, message...), signature...))
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+
+	return token
+}
+
+// decodePASETO splitting v4.public token to message, signature and footer
+func decodePASETO(token string) (message, signature, footer []byte, err error) {
+	if !strings.HasPrefix(token, pasetoHeader) {
+		return nil, nil, nil, fmt.Errorf("token is not %s token", strings.TrimSuffix(pasetoHeader, "."))
+	}
+	encodedPayload, encodedFooter, _ := strings.Cut(strings.TrimPrefix(token, pasetoHeader), ".")
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) < ed25519.SignatureSize {
+		return nil, nil, nil, fmt.Errorf("payload is malformed")
+	}
+	if footer, err = base64.RawURLEncoding.DecodeString(encodedFooter); err != nil {
+		return nil, nil, nil, fmt.Errorf("footer is malformed")
+	}
+
+	return payload[:len(payload)-ed25519.SignatureSize], payload[len(payload)-ed25519.SignatureSize:], footer, nil
+}
+
+// verifyPASETO checking signature of message and footer
+func verifyPASETO(public ed25519.PublicKey, message, signature, footer []byte) bool {
+	return ed25519.Verify(public, pae([]byte(pasetoHeader), message, footer, nil), signature)
+}
+
+// pae pre-authentication encoding of pieces as described in PASETO specification
+func pae(pieces ...[]byte) []byte {
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(pieces)))
+	encoded := append([]byte{}, length[:]...)
+	for _, piece := range pieces {
+		binary.LittleEndian.PutUint64(length[:], uint64(len(piece)))
+		encoded = append(encoded, length[:]...)
+		encoded = append(encoded, piece...)
+	}
+
+	return encoded
+}
+
+// encodePASETOClaims claims as json with time claims formatted as ISO 8601
+func encodePASETOClaims(claims *CustomClaims) ([]byte, error) {
+	fields, err := claimsFields(claims)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range pasetoTimeClaims {
+		if value, ok := fields[name].(json.Number); ok {
+			seconds, err := value.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("claim %q is not a numeric date: %w", name, err)
+			}
+			fields[name] = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// decodePASETOClaims claims from json with time claims formatted as ISO 8601
+func decodePASETOClaims(message []byte) (*CustomClaims, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return nil, fmt.Errorf("claims are malformed: %w", err)
+	}
+	for _, name := range pasetoTimeClaims {
+		if value, ok := fields[name].(string); ok {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("claim %q is not a date: %w", name, err)
+			}
+			fields[name] = parsed.Unix()
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("claims are malformed: %w", err)
+	}
+	claims := &CustomClaims{}
+	if err = json.Unmarshal(data, claims); err != nil {
+		return nil, fmt.Errorf("claims are malformed: %w", err)
+	}
+
+	return claims, nil
+}
+
+// claimsFields claims as generic json object with numbers kept as they were encoded
+func claimsFields(claims *CustomClaims) (map[string]interface{}, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("claims cannot be encoded: %w", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	fields := map[string]interface{}{}
+	if err = decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("claims cannot be encoded: %w", err)
+	}
+
+	return fields, nil
+}
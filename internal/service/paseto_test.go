@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+// PAE examples from PASETO specification
+func TestPAE(t *testing.T) {
+	tests := []struct {
+		pieces [][]byte
+		want   string
+	}{
+		{pieces: nil, want: "\x00\x00\x00\x00\x00\x00\x00\x00"},
+		{pieces: [][]byte{{}}, want: "\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"},
+		{pieces: [][]byte{[]byte("test")}, want: "\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"},
+	}
+	for _, tt := range tests {
+		if got := pae(tt.pieces...); !bytes.Equal(got, []byte(tt.want)) {
+			t.Errorf("pae(%q) = %q, want %q", tt.pieces, got, tt.want)
+		}
+	}
+}
+
+// Official v4.public test vector 4-S-1
+func TestPASETOVector(t *testing.T) {
+	secret, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	private := ed25519.PrivateKey(secret)
+	message := []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`)
+	token := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+
+	if got := signPASETO(private, message, nil); got != token {
+		t.Fatalf("signPASETO() = %s, want %s", got, token)
+	}
+
+	decoded, signature, footer, err := decodePASETO(token)
+	if err != nil {
+		t.Fatalf("decodePASETO: %v", err)
+	}
+	if !bytes.Equal(decoded, message) || len(footer) != 0 {
+		t.Fatalf("decodePASETO() = %q, %q", decoded, footer)
+	}
+	public := private.Public().(ed25519.PublicKey)
+	if !verifyPASETO(public, decoded, signature, footer) {
+		t.Fatal("verifyPASETO() = false for vector token")
+	}
+
+	tampered := append([]byte{}, decoded...)
+	tampered[len(tampered)-2] = '1'
+	if verifyPASETO(public, tampered, signature, footer) {
+		t.Fatal("verifyPASETO() = true for tampered message")
+	}
+	if verifyPASETO(public, decoded, signature, []byte(`{"kid":"other"}`)) {
+		t.Fatal("verifyPASETO() = true for other footer")
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Expiration time of password reset token
+const passwordResetExp = time.Hour
+
+// Policies applied to passwords found in breached passwords corpus
+const (
+	BreachPolicyReject = "reject"
+	BreachPolicyWarn   = "warn"
+)
+
+// ValidateBreachPolicy check that breach policy is known, so typo is not treated as reject silently
+func ValidateBreachPolicy(policy string) error {
+	switch policy {
+	case BreachPolicyReject, BreachPolicyWarn:
+		return nil
+	default:
+		return fmt.Errorf("password - ValidateBreachPolicy - unsupported breach policy %q", policy)
+	}
+}
+
+// BreachedPasswords corpus of passwords known from data breaches
+//
+//go:generate mockery --name=BreachedPasswords --case=underscore --output=./mocks
+type BreachedPasswords interface {
+	IsBreached(password string) (bool, error)
+}
+
+// ChangePassword service change password of user after checking current one,
+// wrong current password is counted by lockout like failed login,
+// all tokens of other sessions and api keys are invalidated and fresh token pair is issued for current session
+func (u *User) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) (accessToken, refreshToken string, err error) {
+	if sessionID == "" {
+		return "", "", fmt.Errorf("userService - ChangePassword - session is required")
+	}
+
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, userID); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - GetUserByID: %w", err)
+	}
+	ip := ClientIPFromContext(ctx)
+	if err = u.lockout.Check(ctx, user.Login, ip); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - Check: %w", err)
+	}
+	var valid bool
+	if valid, err = u.hasher.Verify(user.Password, currentPassword); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - Verify: %w", err)
+	}
+	if !valid {
+		return "", "", u.loginFailed(ctx, user.Login, ip)
+	}
+	if err = u.lockout.Reset(ctx, user.Login); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - Reset: %w", err)
+	}
+	if err = u.validatePassword(newPassword, user); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - validatePassword: %w", err)
+	}
+	if err = u.checkPasswordHistory(ctx, user, newPassword); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - checkPasswordHistory: %w", err)
+	}
+
+	var session *model.Session
+	if session, err = u.sessions.GetSessionByID(ctx, sessionID); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - GetSessionByID: %w", err)
+	}
+	if session.UserID != user.ID || session.Revoked {
+		return "", "", fmt.Errorf("userService - ChangePassword - Session invalid")
+	}
+
+	if err = u.setPassword(ctx, user.ID, newPassword); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - setPassword: %w", err)
+	}
+
+	if err = u.RevokeOtherSessions(ctx, user.ID, session.ID); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - RevokeOtherSessions: %w", err)
+	}
+	if err = u.tokens.RevokeFamily(ctx, session.ID); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - RevokeFamily: %w", err)
+	}
+	if user.TokenVersion, err = u.versions.Bump(ctx, user.ID); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - Bump: %w", err)
+	}
+	if err = u.apiKeys.RevokeAPIKeysByUser(ctx, user.ID); err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - RevokeAPIKeysByUser: %w", err)
+	}
+
+	accessToken, refreshToken, err = u.createJWT(ctx, user, session)
+	if err != nil {
+		return "", "", fmt.Errorf("userService - ChangePassword - createJWT: %w", err)
+	}
+
+	return
+}
+
+// RequestPasswordReset service send single use password reset link to email of user,
+// unknown logins are not reported and link is created and sent in background,
+// so accounts cannot be enumerated by response or its time
+func (u *User) RequestPasswordReset(ctx context.Context, login string) error {
+	user, err := u.rps.GetUserByLogin(ctx, login)
+	if err != nil {
+		logrus.Debugf("userService - RequestPasswordReset - GetUserByLogin: %v", err)
+		return nil
+	}
+
+	u.inBackground("RequestPasswordReset", func(ctx context.Context) error {
+		token, err := u.createOneTimeToken(ctx, user, model.PurposePasswordReset, passwordResetExp)
+		if err != nil {
+			return fmt.Errorf("createOneTimeToken: %w", err)
+		}
+
+		notification := &model.Notification{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Hello, %s!\n\nFollow the link to set a new password, it is valid for %s:\n%s\n\nIf you did not request it, ignore this message.",
+				user.Name, passwordResetExp, linkWithToken(u.resetURL, token)),
+		}
+		if err = u.notifier.Send(ctx, notification); err != nil {
+			return fmt.Errorf("Send: %w", err)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+// ResetPassword service set new password with token from reset link, token is used only when new password is accepted,
+// all sessions, tokens and other links of user are revoked
+func (u *User) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	var stored *model.OneTimeToken
+	if stored, err = u.oneTimeTokens.GetOneTimeToken(ctx, model.PurposePasswordReset, hashToken(token)); err != nil {
+		return fmt.Errorf("userService - ResetPassword - GetOneTimeToken: %w", err)
+	}
+
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, stored.UserID); err != nil {
+		return fmt.Errorf("userService - ResetPassword - GetUserByID: %w", err)
+	}
+	if err = u.validatePassword(newPassword, user); err != nil {
+		return fmt.Errorf("userService - ResetPassword - validatePassword: %w", err)
+	}
+	if err = u.checkPasswordHistory(ctx, user, newPassword); err != nil {
+		return fmt.Errorf("userService - ResetPassword - checkPasswordHistory: %w", err)
+	}
+
+	// token could be used by concurrent request since it was checked
+	if _, err = u.oneTimeTokens.UseOneTimeToken(ctx, model.PurposePasswordReset, stored.TokenHash); err != nil {
+		return fmt.Errorf("userService - ResetPassword - UseOneTimeToken: %w", err)
+	}
+	if err = u.setPassword(ctx, user.ID, newPassword); err != nil {
+		return fmt.Errorf("userService - ResetPassword - setPassword: %w", err)
+	}
+	if err = u.oneTimeTokens.RevokeOneTimeTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("userService - ResetPassword - RevokeOneTimeTokens: %w", err)
+	}
+	if err = u.RevokeAllTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("userService - ResetPassword - RevokeAllTokens: %w", err)
+	}
+
+	return
+}
+
+// rehashPassword upgrading hash of verified password made with outdated algorithm or parameters,
+// failure is only logged because login itself succeeded
+func (u *User) rehashPassword(ctx context.Context, user *model.User, password string) {
+	if !u.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := u.hasher.Hash(password)
+	if err != nil {
+		logrus.Error(fmt.Errorf("userService - rehashPassword - Hash: %w", err))
+		return
+	}
+	if err = u.rps.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+		logrus.Error(fmt.Errorf("userService - rehashPassword - UpdatePasswordHash: %w", err))
+		return
+	}
+	user.Password = hash
+}
+
+// validatePassword checking new password of user against password policy and screening it against breached passwords,
+// breached password is only logged with warn policy
+func (u *User) validatePassword(password string, user *model.User) error {
+	violations := u.policy.Validate(password, user)
+
+	if u.breached != nil {
+		breached, err := u.breached.IsBreached(password)
+		if err != nil {
+			return fmt.Errorf("userService - validatePassword - IsBreached: %w", err)
+		}
+		if breached && u.breachPolicy == BreachPolicyWarn {
+			logrus.Warn("userService - validatePassword - password found in breached passwords is accepted")
+		} else if breached {
+			violations = append(violations, PasswordViolation{
+				Rule:        PasswordRuleBreached,
+				Description: "password was found in breached passwords",
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("userService - validatePassword: %w", &PasswordPolicyError{Violations: violations})
+	}
+
+	return nil
+}
+
+// setPassword hashing and storing new password of user and recording it in password history
+func (u *User) setPassword(ctx context.Context, userID, password string) error {
+	hash, err := u.hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("userService - setPassword - Hash: %w", err)
+	}
+	if err = u.rps.SetPassword(ctx, userID, hash); err != nil {
+		return fmt.Errorf("userService - setPassword - SetPassword: %w", err)
+	}
+	if err = u.addPasswordHistory(ctx, userID, hash); err != nil {
+		return fmt.Errorf("userService - setPassword - addPasswordHistory: %w", err)
+	}
+
+	return nil
+}
+
+// addPasswordHistory recording password hash, older hashes than history size are pruned
+func (u *User) addPasswordHistory(ctx context.Context, userID, hash string) error {
+	if u.historySize <= 0 {
+		return nil
+	}
+	if err := u.history.AddPasswordHistory(ctx, userID, hash, u.historySize); err != nil {
+		return fmt.Errorf("userService - addPasswordHistory - AddPasswordHistory: %w", err)
+	}
+
+	return nil
+}
+
+// checkPasswordHistory rejecting password which is current one or one of the latest ones
+func (u *User) checkPasswordHistory(ctx context.Context, user *model.User, password string) error {
+	if u.historySize <= 0 {
+		return nil
+	}
+
+	hashes, err := u.history.GetPasswordHistory(ctx, user.ID, u.historySize)
+	if err != nil {
+		return fmt.Errorf("userService - checkPasswordHistory - GetPasswordHistory: %w", err)
+	}
+	for _, hash := range append([]string{user.Password}, hashes...) {
+		used, err := u.hasher.Verify(hash, password)
+		if err != nil {
+			return fmt.Errorf("userService - checkPasswordHistory - Verify: %w", err)
+		}
+		if used {
+			return fmt.Errorf("userService - checkPasswordHistory: %w", &PasswordPolicyError{Violations: []PasswordViolation{{
+				Rule:        PasswordRuleHistory,
+				Description: "password was used recently",
+			}}})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashing passwords into PHC strings and verifying them
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash stored hash was made with other algorithm or parameters than configured ones
+	NeedsRehash(hash string) bool
+}
+
+// Argon2Params parameters of argon2id, memory is in KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Smallest argon2id salt and key lengths in bytes
+const (
+	minArgon2SaltLength = 8
+	minArgon2KeyLength  = 16
+)
+
+// ValidateArgon2Params check that parameters give usable hashes, zero parallelism would panic in argon2
+func ValidateArgon2Params(params Argon2Params) error {
+	switch {
+	case params.Iterations == 0:
+		return fmt.Errorf("argon2id - ValidateArgon2Params - iterations must be positive")
+	case params.Parallelism == 0:
+		return fmt.Errorf("argon2id - ValidateArgon2Params - parallelism must be positive")
+	case params.Memory < 8*uint32(params.Parallelism):
+		return fmt.Errorf("argon2id - ValidateArgon2Params - memory must be at least 8 KiB per thread")
+	case params.SaltLength < minArgon2SaltLength:
+		return fmt.Errorf("argon2id - ValidateArgon2Params - salt must be at least %d bytes", minArgon2SaltLength)
+	case params.KeyLength < minArgon2KeyLength:
+		return fmt.Errorf("argon2id - ValidateArgon2Params - key must be at least %d bytes", minArgon2KeyLength)
+	default:
+		return nil
+	}
+}
+
+// Argon2id argon2id password hasher, bcrypt hashes made before are still verified
+type Argon2id struct {
+	params Argon2Params
+}
+
+// NewArgon2id new argon2id password hasher
+func NewArgon2id(params Argon2Params) *Argon2id {
+	return &Argon2id{params: params}
+}
+
+// Hash hashing password with random salt into $argon2id$v=19$m=...,t=...,p=...$salt$hash
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("argon2id - Hash - Read: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+
+	return encodeArgon2id(a.params, salt, key), nil
+}
+
+// Verify checking password against argon2id or bcrypt hash
+func (a *Argon2id) Verify(hash, password string) (bool, error) {
+	if isBcrypt(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("argon2id - Verify - CompareHashAndPassword: %w", err)
+		}
+		return true, nil
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, fmt.Errorf("argon2id - Verify - decodeArgon2id: %w", err)
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash bcrypt hashes and argon2id hashes with other parameters need rehash
+func (a *Argon2id) NeedsRehash(hash string) bool {
+	params, _, _, err := decodeArgon2id(hash)
+	return err != nil || params != a.params
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func encodeArgon2id(params Argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2id(hash string) (params Argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("hash is not argon2id PHC string")
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("version is malformed: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("version %d is not supported", version)
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("parameters are malformed: %w", err)
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("parameters are invalid")
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, fmt.Errorf("salt is malformed: %w", err)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, fmt.Errorf("key is malformed: %w", err)
+	}
+	params.SaltLength, params.KeyLength = uint32(len(salt)), uint32(len(key))
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	passwordvalidator "github.com/wagslane/go-password-validator"
+)
+
+// Rules of password policy reported in violations
+const (
+	PasswordRuleEntropy   = "entropy"
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleUserInfo  = "user_info"
+	PasswordRuleLower     = "lowercase"
+	PasswordRuleUpper     = "uppercase"
+	PasswordRuleDigit     = "digit"
+	PasswordRuleSpecial   = "special"
+	PasswordRuleBreached  = "breached"
+	PasswordRuleHistory   = "history"
+)
+
+// Shortest login, email or name which is forbidden in password, shorter ones match too many passwords
+const minUserInfoLength = 3
+
+// ErrPasswordExpired password is older than maximum age of password policy
+var ErrPasswordExpired = errors.New("password expired")
+
+// PasswordPolicy rules for new passwords, zero values disable rule
+type PasswordPolicy struct {
+	MinEntropy     float64
+	MinLength      int
+	MaxLength      int
+	ForbidUserInfo bool
+	RequireLower   bool
+	RequireUpper   bool
+	RequireDigit   bool
+	RequireSpecial bool
+	MaxAge         time.Duration
+}
+
+// PasswordViolation broken rule of password policy
+type PasswordViolation struct {
+	Rule        string
+	Description string
+}
+
+// PasswordPolicyError new password does not satisfy password policy
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+// Error joined descriptions of violations
+func (e *PasswordPolicyError) Error() string {
+	descriptions := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		descriptions = append(descriptions, v.Description)
+	}
+	return "password policy violated: " + strings.Join(descriptions, "; ")
+}
+
+// Validate all violations of policy by password, user info is checked when user is not nil
+func (p *PasswordPolicy) Validate(password string, user *model.User) []PasswordViolation {
+	var violations []PasswordViolation
+	violate := func(rule, description string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Description: description})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		violate(PasswordRuleMinLength, fmt.Sprintf("password must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violate(PasswordRuleMaxLength, fmt.Sprintf("password must be at most %d characters long", p.MaxLength))
+	}
+	if p.MinEntropy > 0 {
+		if err := passwordvalidator.Validate(password, p.MinEntropy); err != nil {
+			violate(PasswordRuleEntropy, err.Error())
+		}
+	}
+
+	var lower, upper, digit, special bool
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsDigit(c):
+			digit = true
+		default:
+			special = true
+		}
+	}
+	if p.RequireLower && !lower {
+		violate(PasswordRuleLower, "password must contain a lowercase letter")
+	}
+	if p.RequireUpper && !upper {
+		violate(PasswordRuleUpper, "password must contain an uppercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violate(PasswordRuleDigit, "password must contain a digit")
+	}
+	if p.RequireSpecial && !special {
+		violate(PasswordRuleSpecial, "password must contain a special character")
+	}
+
+	if p.ForbidUserInfo && user != nil {
+		lowered := strings.ToLower(password)
+		for _, info := range []struct{ name, value string }{
+			{"login", user.Login},
+			{"email", strings.Split(user.Email, "@")[0]},
+			{"name", user.Name},
+		} {
+			if utf8.RuneCountInString(info.value) >= minUserInfoLength && strings.Contains(lowered, strings.ToLower(info.value)) {
+				violate(PasswordRuleUserInfo, fmt.Sprintf("password must not contain %s", info.name))
+			}
+		}
+	}
+
+	return violations
+}
+
+// Expired check if password set at changed is older than maximum age
+func (p *PasswordPolicy) Expired(changed time.Time) bool {
+	return p.MaxAge > 0 && time.Since(changed) > p.MaxAge
+}
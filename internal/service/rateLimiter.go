@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitRepository repository interface for token buckets of rate limits
+//
+//go:generate mockery --name=RateLimitRepository --case=underscore --output=./mocks
+type RateLimitRepository interface {
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (time.Duration, error)
+	DeleteFullRateLimits(ctx context.Context) error
+}
+
+// RateLimiter token buckets of rate limits
+type RateLimiter struct {
+	rps RateLimitRepository
+}
+
+// NewRateLimiter new rate limiter
+func NewRateLimiter(rps RateLimitRepository) *RateLimiter {
+	return &RateLimiter{rps: rps}
+}
+
+// Allow take token from bucket of key refilled with rate tokens per second up to burst,
+// zero is returned when request is allowed, otherwise time after which it can be retried
+func (l *RateLimiter) Allow(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	retryAfter, err := l.rps.TakeToken(ctx, key, rate, burst)
+	if err != nil {
+		return 0, fmt.Errorf("rateLimiter - Allow - TakeToken: %w", err)
+	}
+
+	return retryAfter, nil
+}
+
+// Cleanup removing refilled buckets every interval until context is done
+func (l *RateLimiter) Cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.rps.DeleteFullRateLimits(ctx); err != nil {
+				logrus.Error(fmt.Errorf("rateLimiter - Cleanup - DeleteFullRateLimits: %w", err))
+			}
+		}
+	}
+}
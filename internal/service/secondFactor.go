@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Expiration time of login challenge
+const challengeExp = time.Minute * 5
+
+// Size of random opaque tokens
+const opaqueTokenBytes = 32
+
+// EnrollTOTP service generate new totp secret, it is not required on login until confirmed
+func (u *User) EnrollTOTP(ctx context.Context, userID string) (enrollment *model.TOTPEnrollment, err error) {
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("userService - EnrollTOTP - GetUserByID: %w", err)
+	}
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("userService - EnrollTOTP - totp already enabled")
+	}
+
+	var secret, encrypted string
+	if secret, err = generateTOTPSecret(); err != nil {
+		return nil, fmt.Errorf("userService - EnrollTOTP - generateTOTPSecret: %w", err)
+	}
+	if encrypted, err = encryptSecret(u.totpKey, secret); err != nil {
+		return nil, fmt.Errorf("userService - EnrollTOTP - encryptSecret: %w", err)
+	}
+	if err = u.rps.SetTOTP(ctx, user.ID, encrypted, false); err != nil {
+		return nil, fmt.Errorf("userService - EnrollTOTP - SetTOTP: %w", err)
+	}
+
+	return &model.TOTPEnrollment{
+		Secret: secret,
+		URI:    totpURI(u.totpIssuer, user.Login, secret),
+	}, nil
+}
+
+// ConfirmTOTP service enable second factor after checking first code, returns one time recovery codes
+func (u *User) ConfirmTOTP(ctx context.Context, userID, code string) (recoveryCodes []string, err error) {
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - GetUserByID: %w", err)
+	}
+	if user.TOTPEnabled || user.TOTPSecret == "" {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - totp is not enrolled")
+	}
+
+	var secret string
+	if secret, err = decryptSecret(u.totpKey, user.TOTPSecret); err != nil {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - decryptSecret: %w", err)
+	}
+	var valid bool
+	if valid, err = u.useTOTP(ctx, user, secret, code); err != nil {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - useTOTP: %w", err)
+	}
+	if !valid {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - code invalid")
+	}
+
+	if recoveryCodes, err = generateRecoveryCodes(); err != nil {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - generateRecoveryCodes: %w", err)
+	}
+	hashes := make([]string, 0, len(recoveryCodes))
+	for _, recoveryCode := range recoveryCodes {
+		hashes = append(hashes, hashToken(recoveryCode))
+	}
+	if err = u.secondFactors.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - ReplaceRecoveryCodes: %w", err)
+	}
+	if err = u.rps.SetTOTP(ctx, user.ID, user.TOTPSecret, true); err != nil {
+		return nil, fmt.Errorf("userService - ConfirmTOTP - SetTOTP: %w", err)
+	}
+
+	return
+}
+
+// DisableTOTP service disable second factor, current totp or recovery code is required,
+// failed codes are counted like in VerifySecondFactor
+func (u *User) DisableTOTP(ctx context.Context, userID, code string) (err error) {
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, userID); err != nil {
+		return fmt.Errorf("userService - DisableTOTP - GetUserByID: %w", err)
+	}
+	if !user.TOTPEnabled {
+		return fmt.Errorf("userService - DisableTOTP - totp is not enabled")
+	}
+
+	ip := ClientIPFromContext(ctx)
+	if err = u.lockout.CheckSecondFactor(ctx, user.ID, ip); err != nil {
+		return fmt.Errorf("userService - DisableTOTP - CheckSecondFactor: %w", err)
+	}
+	var valid bool
+	if valid, err = u.checkSecondFactor(ctx, user, code); err != nil {
+		return fmt.Errorf("userService - DisableTOTP - checkSecondFactor: %w", err)
+	}
+	if !valid {
+		return u.secondFactorFailed(ctx, user.ID, ip)
+	}
+
+	if err = u.rps.SetTOTP(ctx, user.ID, "", false); err != nil {
+		return fmt.Errorf("userService - DisableTOTP - SetTOTP: %w", err)
+	}
+	if err = u.secondFactors.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+		return fmt.Errorf("userService - DisableTOTP - DeleteRecoveryCodes: %w", err)
+	}
+
+	return
+}
+
+// VerifySecondFactor service finish login started with challenge token using totp or recovery code,
+// failed codes are counted per user across challenges and lock second factor out after threshold
+func (u *User) VerifySecondFactor(ctx context.Context, challengeToken, code string, session *model.Session) (accessToken, refreshToken string, err error) {
+	var challenge *model.LoginChallenge
+	if challenge, err = u.secondFactors.AttemptChallenge(ctx, hashToken(challengeToken)); err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - AttemptChallenge: %w", err)
+	}
+
+	var user *model.User
+	if user, err = u.rps.GetUserByID(ctx, challenge.UserID); err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - GetUserByID: %w", err)
+	}
+	if err = u.lockout.CheckSecondFactor(ctx, user.ID, session.IP); err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - CheckSecondFactor: %w", err)
+	}
+
+	var valid bool
+	if valid, err = u.checkTOTP(ctx, user, code); err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - checkTOTP: %w", err)
+	}
+	if valid {
+		if valid, err = u.secondFactors.CompleteChallenge(ctx, challenge.ID); err != nil || !valid {
+			return "", "", fmt.Errorf("userService - VerifySecondFactor - CompleteChallenge: challenge already used: %w", err)
+		}
+	} else {
+		// recovery code is used only together with challenge, so it is not lost when challenge cannot be completed
+		valid, err = u.secondFactors.CompleteChallengeWithRecoveryCode(ctx, challenge.ID, user.ID, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return "", "", fmt.Errorf("userService - VerifySecondFactor - CompleteChallengeWithRecoveryCode: %w", err)
+		}
+	}
+	if !valid {
+		return "", "", u.secondFactorFailed(ctx, user.ID, session.IP)
+	}
+	if err = u.lockout.ResetSecondFactor(ctx, user.ID); err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - ResetSecondFactor: %w", err)
+	}
+
+	session.DeviceName, session.Remember = challenge.DeviceName, challenge.Remember
+	if err = u.createSession(ctx, user, session); err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - createSession: %w", err)
+	}
+
+	accessToken, refreshToken, err = u.createJWT(ctx, user, session)
+	if err != nil {
+		return "", "", fmt.Errorf("userService - VerifySecondFactor - createJWT: %w", err)
+	}
+
+	return
+}
+
+// secondFactorFailed counting failed second factor code of user
+func (u *User) secondFactorFailed(ctx context.Context, userID, ip string) error {
+	if err := u.lockout.FailSecondFactor(ctx, userID, ip); err != nil {
+		return fmt.Errorf("userService - secondFactorFailed - FailSecondFactor: %w", err)
+	}
+
+	return fmt.Errorf("userService - secondFactorFailed - code invalid")
+}
+
+// checkSecondFactor checking code as totp code and then as recovery code
+func (u *User) checkSecondFactor(ctx context.Context, user *model.User, code string) (bool, error) {
+	valid, err := u.checkTOTP(ctx, user, code)
+	if err != nil {
+		return false, fmt.Errorf("userService - checkSecondFactor - checkTOTP: %w", err)
+	}
+	if valid {
+		return true, nil
+	}
+
+	used, err := u.secondFactors.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("userService - checkSecondFactor - UseRecoveryCode: %w", err)
+	}
+
+	return used, nil
+}
+
+// checkTOTP checking code as totp code of user
+func (u *User) checkTOTP(ctx context.Context, user *model.User, code string) (bool, error) {
+	secret, err := decryptSecret(u.totpKey, user.TOTPSecret)
+	if err != nil {
+		return false, fmt.Errorf("userService - checkTOTP - decryptSecret: %w", err)
+	}
+	valid, err := u.useTOTP(ctx, user, secret, code)
+	if err != nil {
+		return false, fmt.Errorf("userService - checkTOTP - useTOTP: %w", err)
+	}
+
+	return valid, nil
+}
+
+// useTOTP checking totp code and marking its time step as used, code of already used step is rejected
+func (u *User) useTOTP(ctx context.Context, user *model.User, secret, code string) (bool, error) {
+	step, valid := validateTOTP(secret, code, time.Now())
+	if !valid {
+		return false, nil
+	}
+
+	fresh, err := u.rps.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return false, fmt.Errorf("userService - useTOTP - UseTOTPStep: %w", err)
+	}
+
+	return fresh, nil
+}
+
+func (u *User) createChallenge(ctx context.Context, user *model.User, session *model.Session) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("userService - createChallenge - generateOpaqueToken: %w", err)
+	}
+
+	challenge := &model.LoginChallenge{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		TokenHash:  hashToken(token),
+		DeviceName: session.DeviceName,
+		Remember:   session.Remember,
+		Expires:    time.Now().Add(challengeExp),
+	}
+	if err = u.secondFactors.CreateChallenge(ctx, challenge); err != nil {
+		return "", fmt.Errorf("userService - createChallenge - CreateChallenge: %w", err)
+	}
+
+	return token, nil
+}
+
+// generateOpaqueToken random url safe token
+func generateOpaqueToken() (string, error) {
+	raw := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("userService - generateOpaqueToken - Read: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
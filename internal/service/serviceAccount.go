@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Size of random part of client id
+const clientIDBytes = 8
+
+// ErrServiceAccountRole role which cannot be granted to service account
+var ErrServiceAccountRole = errors.New("service account may only have service role")
+
+// CreateServiceAccount service create service account owned by admin, only service role is allowed,
+// client secret is returned only once
+func (u *User) CreateServiceAccount(ctx context.Context, ownerID, name, role string) (clientSecret string, account *model.ServiceAccount, err error) {
+	if role == "" {
+		role = model.RoleService
+	}
+	if role != model.RoleService {
+		return "", nil, fmt.Errorf("userService - CreateServiceAccount - %w: %q", ErrServiceAccountRole, role)
+	}
+
+	clientID := make([]byte, clientIDBytes)
+	if _, err = rand.Read(clientID); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateServiceAccount - Read: %w", err)
+	}
+	if clientSecret, err = generateOpaqueToken(); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateServiceAccount - generateOpaqueToken: %w", err)
+	}
+
+	account = &model.ServiceAccount{
+		ID:         uuid.New().String(),
+		Name:       name,
+		ClientID:   model.ClientIDPrefix + hex.EncodeToString(clientID),
+		SecretHash: hashToken(clientSecret),
+		Role:       role,
+		OwnerID:    ownerID,
+	}
+	if err = u.serviceAccounts.CreateServiceAccount(ctx, account); err != nil {
+		return "", nil, fmt.Errorf("userService - CreateServiceAccount - CreateServiceAccount: %w", err)
+	}
+
+	return
+}
+
+// DeleteServiceAccount service delete service account, its issued tokens are rejected by introspection
+func (u *User) DeleteServiceAccount(ctx context.Context, id string) (err error) {
+	if err = u.serviceAccounts.DeleteServiceAccount(ctx, id); err != nil {
+		return fmt.Errorf("userService - DeleteServiceAccount - DeleteServiceAccount: %w", err)
+	}
+
+	return
+}
+
+// CheckServiceAccount service check that service account exists and was not deleted,
+// like in introspection any failed lookup is treated as deleted account
+func (u *User) CheckServiceAccount(ctx context.Context, id string) error {
+	if _, err := u.serviceAccounts.GetServiceAccountByID(ctx, id); err != nil {
+		return fmt.Errorf("userService - CheckServiceAccount - GetServiceAccountByID: %w", err)
+	}
+
+	return nil
+}
+
+// ClientCredentials service client credentials grant, only access token is issued
+func (u *User) ClientCredentials(ctx context.Context, clientID, clientSecret string) (accessToken string, err error) {
+	var account *model.ServiceAccount
+	if account, err = u.serviceAccounts.GetServiceAccountByClientID(ctx, clientID); err != nil {
+		return "", fmt.Errorf("userService - ClientCredentials - GetServiceAccountByClientID: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(account.SecretHash), []byte(hashToken(clientSecret))) != 1 {
+		return "", fmt.Errorf("userService - ClientCredentials - Client secret invalid")
+	}
+
+	claims := &CustomClaims{
+		ID:       account.ID,
+		Role:     account.Role,
+		Type:     TokenTypeAccess,
+		ClientID: account.ClientID,
+		Scopes:   model.RoleScopes(account.Role),
+		RegisteredClaims: u.registeredClaims(account.ID, uuid.New().String(), u.audiences,
+			time.Now().Add(u.lifetimes.ForRole(account.Role).Access)),
+	}
+	if accessToken, err = u.signToken(claims); err != nil {
+		return "", fmt.Errorf("userService - ClientCredentials - signToken: %w", err)
+	}
+
+	return
+}
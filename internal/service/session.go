@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ListSessions service active sessions of user
+func (u *User) ListSessions(ctx context.Context, userID string) (sessions []*model.Session, err error) {
+	if sessions, err = u.sessions.GetSessionsByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("userService - ListSessions - GetSessionsByUser: %w", err)
+	}
+
+	return
+}
+
+// RevokeSession service revoke session of user with its refresh tokens
+func (u *User) RevokeSession(ctx context.Context, userID, sessionID string) (err error) {
+	if err = u.sessions.RevokeSession(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("userService - RevokeSession - RevokeSession: %w", err)
+	}
+	if err = u.tokens.RevokeFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("userService - RevokeSession - RevokeFamily: %w", err)
+	}
+
+	return
+}
+
+// RevokeOtherSessions service revoke all sessions of user except current one
+func (u *User) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (err error) {
+	if err = u.sessions.RevokeOtherSessions(ctx, userID, currentSessionID); err != nil {
+		return fmt.Errorf("userService - RevokeOtherSessions - RevokeOtherSessions: %w", err)
+	}
+	if err = u.tokens.RevokeOtherFamilies(ctx, userID, currentSessionID); err != nil {
+		return fmt.Errorf("userService - RevokeOtherSessions - RevokeOtherFamilies: %w", err)
+	}
+
+	return
+}
+
+// CheckSession service check that session exists and was not revoked,
+// like in introspection any failed lookup is treated as revoked session
+func (u *User) CheckSession(ctx context.Context, id string) error {
+	session, err := u.sessions.GetSessionByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("userService - CheckSession - GetSessionByID: %w", err)
+	}
+	if session.Revoked {
+		return fmt.Errorf("userService - CheckSession - session revoked")
+	}
+
+	return nil
+}
+
+// Logout service revoke current session and deny its access token, tokens without session are only denied,
+// api keys have no session to end and must be revoked instead
+func (u *User) Logout(ctx context.Context, claims *CustomClaims) (err error) {
+	if claims.Type == TokenTypeAPIKey {
+		return fmt.Errorf("userService - Logout - api key cannot log out, revoke it instead")
+	}
+	if claims.SessionID != "" {
+		if err = u.RevokeSession(ctx, claims.ID, claims.SessionID); err != nil {
+			return fmt.Errorf("userService - Logout - RevokeSession: %w", err)
+		}
+	}
+	if claims.ExpiresAt != nil {
+		if err = u.denylist.Add(ctx, claims.RegisteredClaims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("userService - Logout - Add: %w", err)
+		}
+	}
+
+	return
+}
+
+// startSession starting session of user whose first factor is verified,
+// users with second factor get only challenge token
+func (u *User) startSession(ctx context.Context, user *model.User, session *model.Session) (accessToken, refreshToken, challengeToken string, err error) {
+	if user.TOTPEnabled {
+		if challengeToken, err = u.createChallenge(ctx, user, session); err != nil {
+			return "", "", "", fmt.Errorf("userService - startSession - createChallenge: %w", err)
+		}
+		return "", "", challengeToken, nil
+	}
+
+	if err = u.createSession(ctx, user, session); err != nil {
+		return "", "", "", fmt.Errorf("userService - startSession - createSession: %w", err)
+	}
+
+	accessToken, refreshToken, err = u.createJWT(ctx, user, session)
+	if err != nil {
+		return "", "", "", fmt.Errorf("userService - startSession - createJWT: %w", err)
+	}
+
+	return
+}
+
+func (u *User) createSession(ctx context.Context, user *model.User, session *model.Session) error {
+	session.ID = uuid.New().String()
+	session.UserID = user.ID
+	if err := u.sessions.CreateSession(ctx, session); err != nil {
+		return fmt.Errorf("userService - createSession - CreateSession: %w", err)
+	}
+
+	return nil
+}
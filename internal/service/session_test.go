@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type fakeSessions struct {
+	SessionRepository
+	revoked []string
+}
+
+func (f *fakeSessions) RevokeSession(_ context.Context, _, id string) error {
+	f.revoked = append(f.revoked, id)
+	return nil
+}
+
+type fakeRefreshTokens struct {
+	RefreshTokenRepository
+}
+
+func (f *fakeRefreshTokens) RevokeFamily(context.Context, string) error {
+	return nil
+}
+
+type fakeDenylist struct {
+	DenylistRepository
+	denied []string
+}
+
+func (f *fakeDenylist) AddToDenylist(_ context.Context, jti string, _ time.Time) error {
+	f.denied = append(f.denied, jti)
+	return nil
+}
+
+func newLogoutUser() (*User, *fakeSessions, *fakeDenylist) {
+	sessions := &fakeSessions{}
+	denied := &fakeDenylist{}
+	return &User{sessions: sessions, tokens: &fakeRefreshTokens{}, denylist: NewDenylist(denied)}, sessions, denied
+}
+
+func TestLogoutAPIKey(t *testing.T) {
+	u, sessions, denied := newLogoutUser()
+	claims := &CustomClaims{
+		ID:               "user",
+		Type:             TokenTypeAPIKey,
+		RegisteredClaims: jwt.RegisteredClaims{ID: "key", Subject: "user"},
+	}
+
+	if err := u.Logout(context.Background(), claims); err == nil {
+		t.Fatal("logout with api key is accepted")
+	}
+	if len(sessions.revoked) != 0 || len(denied.denied) != 0 {
+		t.Fatalf("logout with api key revoked %v and denied %v", sessions.revoked, denied.denied)
+	}
+}
+
+func TestLogoutWithoutExpiration(t *testing.T) {
+	u, _, denied := newLogoutUser()
+	claims := &CustomClaims{
+		ID:               "user",
+		Type:             TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti", Subject: "user"},
+	}
+
+	if err := u.Logout(context.Background(), claims); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if len(denied.denied) != 0 {
+		t.Fatalf("token without expiration is denied: %v", denied.denied)
+	}
+}
+
+func TestLogoutSession(t *testing.T) {
+	u, sessions, denied := newLogoutUser()
+	claims := &CustomClaims{
+		ID:        "user",
+		Type:      TokenTypeAccess,
+		SessionID: "session",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			Subject:   "user",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	if err := u.Logout(context.Background(), claims); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if len(sessions.revoked) != 1 || sessions.revoked[0] != "session" {
+		t.Fatalf("session is not revoked: %v", sessions.revoked)
+	}
+	if len(denied.denied) != 1 || denied.denied[0] != "jti" {
+		t.Fatalf("token is not denied: %v", denied.denied)
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported formats of issued tokens
+const (
+	TokenFormatJWT    = "jwt"
+	TokenFormatPASETO = "paseto"
+)
+
+// TokenIssuer signing claims into token
+type TokenIssuer interface {
+	Issue(claims *CustomClaims) (string, error)
+}
+
+// TokenVerifier checking signature and validity of token and returning its claims
+type TokenVerifier interface {
+	Verify(token string) (*CustomClaims, error)
+}
+
+// TokenFormat format of issued tokens
+type TokenFormat interface {
+	TokenIssuer
+	TokenVerifier
+}
+
+// NewTokenFormat token format by name, tokens are signed with keys of key set
+func NewTokenFormat(name string, keys *KeySet) (TokenFormat, error) {
+	switch name {
+	case TokenFormatJWT:
+		return NewJWT(keys), nil
+	case TokenFormatPASETO:
+		if keys.alg != AlgorithmEdDSA {
+			return nil, fmt.Errorf("token - NewTokenFormat - paseto v4.public requires %s keys", AlgorithmEdDSA)
+		}
+		return NewPASETO(keys), nil
+	default:
+		return nil, fmt.Errorf("token - NewTokenFormat - unsupported token format %q", name)
+	}
+}
+
+// JWT tokens in JWS compact serialization, kid header selects verification key
+type JWT struct {
+	keys *KeySet
+}
+
+// NewJWT new jwt token format
+func NewJWT(keys *KeySet) *JWT {
+	return &JWT{keys: keys}
+}
+
+// Issue signing claims with current key
+func (t *JWT) Issue(claims *CustomClaims) (string, error) {
+	key, err := t.keys.Current()
+	if err != nil {
+		return "", fmt.Errorf("jwt - Issue - Current: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	tokenStr, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", fmt.Errorf("jwt - Issue - SignedString: %w", err)
+	}
+
+	return tokenStr, nil
+}
+
+// Verify parsing token signed with one of valid keys
+func (t *JWT) Verify(token string) (*CustomClaims, error) {
+	claims := &CustomClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, t.keys.KeyFunc); err != nil {
+		return nil, fmt.Errorf("jwt - Verify - ParseWithClaims: %w", err)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Maximum expiration time of exchanged token, it never outlives subject token
+const tokenExchangeExp = time.Minute * 5
+
+// ExchangeToken service token exchange as described in RFC 8693, caller gets token of subject
+// restricted to one of configured audiences and subset of subject scopes,
+// caller is added to delegation chain as actor unless it is the subject itself,
+// only service accounts can exchange tokens and subject token must pass the same revocation checks as on authorization
+func (u *User) ExchangeToken(ctx context.Context, caller *CustomClaims, subjectToken, audience string, scopes []string) (accessToken string,
+	expires time.Time, granted []string, err error) {
+	if caller.ClientID == "" || !model.HasScope(caller.Scopes, model.ScopeExchange) {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - caller is not allowed to exchange tokens")
+	}
+	if !contains(u.audiences, audience) {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - audience %q is not allowed", audience)
+	}
+
+	var subject *CustomClaims
+	if subject, err = u.tokenFormat.Verify(subjectToken); err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - Verify: %w", err)
+	}
+	if subject.Type != TokenTypeAccess || !subject.VerifyIssuer(u.issuer, true) {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - subject token is not an access token")
+	}
+
+	var denied bool
+	if denied, err = u.denylist.IsDenied(ctx, subject.RegisteredClaims.ID); err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - IsDenied: %w", err)
+	}
+	if denied {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - subject token is revoked")
+	}
+	if err = u.checkSubject(ctx, subject); err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - checkSubject: %w", err)
+	}
+
+	available := subject.Scopes
+	if available == nil {
+		available = model.RoleScopes(subject.Role)
+	}
+	if granted, err = downscope(available, scopes); err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - downscope: %w", err)
+	}
+
+	expires = time.Now().Add(tokenExchangeExp)
+	if subject.ExpiresAt != nil && subject.ExpiresAt.Time.Before(expires) {
+		expires = subject.ExpiresAt.Time
+	}
+
+	actor := subject.Actor
+	if caller.ID != subject.ID {
+		actor = &model.Actor{Subject: callerSubject(caller), Actor: subject.Actor}
+	}
+
+	// session of subject is not copied, so holder of exchanged token cannot end or manage it
+	claims := &CustomClaims{
+		ID:               subject.ID,
+		Role:             subject.Role,
+		Type:             TokenTypeAccess,
+		ClientID:         subject.ClientID,
+		Scopes:           granted,
+		Actor:            actor,
+		Version:          subject.Version,
+		RegisteredClaims: u.registeredClaims(subject.ID, uuid.New().String(), []string{audience}, expires),
+	}
+	if accessToken, err = u.signToken(claims); err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("userService - ExchangeToken - signToken: %w", err)
+	}
+
+	return
+}
+
+// checkSubject rejecting token of revoked session, token older than token version of user
+// and token of deleted service account
+func (u *User) checkSubject(ctx context.Context, subject *CustomClaims) error {
+	if subject.ClientID != "" {
+		if _, err := u.serviceAccounts.GetServiceAccountByID(ctx, subject.ID); err != nil {
+			return fmt.Errorf("userService - checkSubject - GetServiceAccountByID: %w", err)
+		}
+		return nil
+	}
+
+	current, err := u.versions.IsCurrent(ctx, subject.ID, subject.Version)
+	if err != nil {
+		return fmt.Errorf("userService - checkSubject - IsCurrent: %w", err)
+	}
+	if !current {
+		return fmt.Errorf("userService - checkSubject - token version is outdated")
+	}
+	if subject.SessionID != "" {
+		session, err := u.sessions.GetSessionByID(ctx, subject.SessionID)
+		if err != nil {
+			return fmt.Errorf("userService - checkSubject - GetSessionByID: %w", err)
+		}
+		if session.Revoked {
+			return fmt.Errorf("userService - checkSubject - session is revoked")
+		}
+	}
+
+	return nil
+}
+
+// downscope requested scopes if all of them are available, all available scopes if none are requested
+func downscope(available, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return available, nil
+	}
+	for _, scope := range requested {
+		if !model.HasScope(available, scope) {
+			return nil, fmt.Errorf("scope %q is not granted to subject", scope)
+		}
+	}
+
+	return requested, nil
+}
+
+// callerSubject identifier of caller in actor claim, service accounts are identified by client id
+func callerSubject(caller *CustomClaims) string {
+	if caller.ClientID != "" {
+		return caller.ClientID
+	}
+	return caller.ID
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
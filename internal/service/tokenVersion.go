@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenVersionRepository repository interface for token versions of users
+//
+//go:generate mockery --name=TokenVersionRepository --case=underscore --output=./mocks
+type TokenVersionRepository interface {
+	GetTokenVersion(ctx context.Context, userID string) (int, error)
+	IncrementTokenVersion(ctx context.Context, userID string) (int, error)
+}
+
+// Number of cached versions after which expired ones are dropped
+const tokenVersionCacheSize = 10000
+
+type tokenVersionEntry struct {
+	version int
+	expires time.Time
+}
+
+// TokenVersions current token versions of users with in-process cache in front of repository,
+// tokens with older version than the current one are rejected
+type TokenVersions struct {
+	rps   TokenVersionRepository
+	ttl   time.Duration
+	mu    sync.RWMutex
+	cache map[string]tokenVersionEntry
+}
+
+// NewTokenVersions new token versions, stored version is trusted for ttl without asking repository
+func NewTokenVersions(rps TokenVersionRepository, ttl time.Duration) *TokenVersions {
+	return &TokenVersions{rps: rps, ttl: ttl, cache: make(map[string]tokenVersionEntry)}
+}
+
+// IsCurrent check if token version is not older than current version of user
+func (v *TokenVersions) IsCurrent(ctx context.Context, userID string, version int) (bool, error) {
+	current, err := v.Current(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("tokenVersions - IsCurrent - Current: %w", err)
+	}
+
+	return version >= current, nil
+}
+
+// Current current token version of user
+func (v *TokenVersions) Current(ctx context.Context, userID string) (int, error) {
+	now := time.Now()
+	v.mu.RLock()
+	entry, ok := v.cache[userID]
+	v.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry.version, nil
+	}
+
+	current, err := v.rps.GetTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("tokenVersions - Current - GetTokenVersion: %w", err)
+	}
+	v.store(userID, current, now)
+
+	return current, nil
+}
+
+// Bump increment token version of user, all tokens issued before are rejected
+func (v *TokenVersions) Bump(ctx context.Context, userID string) (int, error) {
+	current, err := v.rps.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("tokenVersions - Bump - IncrementTokenVersion: %w", err)
+	}
+	v.store(userID, current, time.Now())
+
+	return current, nil
+}
+
+// store caching version, expired entries are dropped when cache grows too big
+func (v *TokenVersions) store(userID string, version int, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.cache) >= tokenVersionCacheSize {
+		for id, entry := range v.cache {
+			if !now.Before(entry.expires) {
+				delete(v.cache, id)
+			}
+		}
+	}
+	v.cache[userID] = tokenVersionEntry{version: version, expires: now.Add(v.ttl)}
+}
+
+// RevokeAllTokens service invalidate all access tokens of user by bumping its token version,
+// sessions, refresh tokens and api keys are revoked too so no new access token can be obtained with them
+func (u *User) RevokeAllTokens(ctx context.Context, userID string) (err error) {
+	if _, err = u.versions.Bump(ctx, userID); err != nil {
+		return fmt.Errorf("userService - RevokeAllTokens - Bump: %w", err)
+	}
+	if err = u.apiKeys.RevokeAPIKeysByUser(ctx, userID); err != nil {
+		return fmt.Errorf("userService - RevokeAllTokens - RevokeAPIKeysByUser: %w", err)
+	}
+	if err = u.RevokeOtherSessions(ctx, userID, ""); err != nil {
+		return fmt.Errorf("userService - RevokeAllTokens - RevokeOtherSessions: %w", err)
+	}
+
+	return
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // RFC 6238 authenticator apps use HMAC-SHA1
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameters of generated totp codes, the ones supported by all authenticator apps
+const (
+	totpPeriod      = 30
+	totpDigits      = 6
+	totpModulo      = 1000000
+	totpSkew        = 1
+	totpSecretBytes = 20
+)
+
+// Number and length of generated recovery codes
+const (
+	recoveryCodesCount = 10
+	recoveryCodeBytes  = 10
+)
+
+// base32 encoding used by authenticator apps
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding) //nolint:gochecknoglobals // immutable encoding
+
+func generateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("totp - generateTOTPSecret - Read: %w", err)
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// totpURI otpauth uri understood by authenticator apps
+func totpURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+// validateTOTP checking code against current time step and its neighbours, returns matched step
+// which must be newer than the last used step of user to prevent replay
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	step := now.Unix() / totpPeriod
+	var matched int64
+	valid := false
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		expected := totpCode(key, step+i)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			matched, valid = step+i, true
+		}
+	}
+
+	return matched, valid
+}
+
+// totpCode code for time step as described in RFC 4226
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo)
+}
+
+// generateRecoveryCodes one time codes of 80 random bits in form XXXXXXXXXXXXXXXX
+func generateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("totp - generateRecoveryCodes - Read: %w", err)
+		}
+		codes = append(codes, totpEncoding.EncodeToString(raw))
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode recovery code as it was generated
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// encryptSecret sealing secret with AES-GCM, nonce is prepended to ciphertext
+func encryptSecret(key []byte, secret string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("totp - encryptSecret - newGCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("totp - encryptSecret - Read: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func decryptSecret(key []byte, encrypted string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("totp - decryptSecret - newGCM: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("totp - decryptSecret - DecodeString: %w", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("totp - decryptSecret - ciphertext too short")
+	}
+
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("totp - decryptSecret - Open: %w", err)
+	}
+
+	return string(secret), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("totp - newGCM - NewCipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
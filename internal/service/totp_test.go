@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OVantsevich/User-Service/internal/model"
+)
+
+// RFC 6238 appendix B test vectors for HMAC-SHA1, codes are truncated to 6 digits
+func TestTOTPCodeRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "94287082"},
+		{unix: 1111111109, code: "07081804"},
+		{unix: 1111111111, code: "14050471"},
+		{unix: 1234567890, code: "89005924"},
+		{unix: 2000000000, code: "69279037"},
+		{unix: 20000000000, code: "65353130"},
+	}
+	for _, tt := range tests {
+		want := tt.code[len(tt.code)-totpDigits:]
+		if got := totpCode(key, tt.unix/totpPeriod); got != want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+type fakeTOTPUsers struct {
+	UserRepository
+	lastStep int64
+	user     *model.User
+}
+
+func (f *fakeTOTPUsers) GetUserByID(context.Context, string) (*model.User, error) {
+	return f.user, nil
+}
+
+func (f *fakeTOTPUsers) UseTOTPStep(_ context.Context, _ string, step int64) (bool, error) {
+	if step <= f.lastStep {
+		return false, nil
+	}
+	f.lastStep = step
+	return true, nil
+}
+
+func TestUseTOTPRejectsReplay(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Now()
+	key, _ := totpEncoding.DecodeString(secret)
+	code := totpCode(key, now.Unix()/totpPeriod)
+
+	u := &User{rps: &fakeTOTPUsers{}}
+	user := &model.User{ID: "user"}
+	valid, err := u.useTOTP(context.Background(), user, secret, code)
+	if err != nil || !valid {
+		t.Fatalf("first use = %v, %v, want valid", valid, err)
+	}
+	valid, err = u.useTOTP(context.Background(), user, secret, code)
+	if err != nil || valid {
+		t.Fatalf("replay = %v, %v, want rejected", valid, err)
+	}
+}
+
+func TestRecoveryCodeEntropy(t *testing.T) {
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	for _, code := range codes {
+		if bits := len(code) * 5; bits < 80 {
+			t.Fatalf("recovery code %q has %d bits", code, bits)
+		}
+	}
+}
+
+type fakeChallenges struct {
+	SecondFactorRepository
+	attempts int
+}
+
+func (f *fakeChallenges) AttemptChallenge(context.Context, string) (*model.LoginChallenge, error) {
+	// every login creates new challenge, so per challenge limit is never reached
+	f.attempts++
+	return &model.LoginChallenge{ID: "challenge", UserID: "user"}, nil
+}
+
+func (f *fakeChallenges) CompleteChallengeWithRecoveryCode(context.Context, string, string, string) (bool, error) {
+	return false, nil
+}
+
+func TestVerifySecondFactorLockout(t *testing.T) {
+	key := make([]byte, 32)
+	encrypted, err := encryptSecret(key, totpEncoding.EncodeToString([]byte("12345678901234567890")))
+	if err != nil {
+		t.Fatalf("encryptSecret: %v", err)
+	}
+	challenges := &fakeChallenges{}
+	u := &User{
+		rps:           &fakeTOTPUsers{user: &model.User{ID: "user", TOTPSecret: encrypted, TOTPEnabled: true}},
+		secondFactors: challenges,
+		totpKey:       key,
+		lockout: NewLockout(newFakeLoginAttempts(), LockoutPolicy{
+			LoginThreshold: 5,
+			BaseLock:       time.Minute,
+			MaxLock:        time.Hour,
+			Window:         time.Hour,
+		}),
+	}
+
+	var locked *LoginLockedError
+	for i := 0; i < 10; i++ {
+		_, _, err = u.VerifySecondFactor(context.Background(), "token", "invalid", &model.Session{IP: "198.51.100.1"})
+		if errors.As(err, &locked) {
+			break
+		}
+	}
+	if locked == nil || challenges.attempts != 6 {
+		t.Fatalf("second factor is locked after %d attempts, want lock after 5 failures", challenges.attempts)
+	}
+}
@@ -3,6 +3,8 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -10,8 +12,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
-	passwordvalidator "github.com/wagslane/go-password-validator"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/sirupsen/logrus"
 )
 
 // UserRepository repository interface for user service
@@ -22,46 +23,237 @@ type UserRepository interface {
 	GetUserByLogin(ctx context.Context, login string) (*model.User, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
 	UpdateUser(ctx context.Context, id string, user *model.User) error
-	RefreshUser(ctx context.Context, id, token string) error
 	DeleteUser(ctx context.Context, id string) error
+	SetTOTP(ctx context.Context, id, secret string, enabled bool) error
+	UseTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+	SetPassword(ctx context.Context, id, password string) error
+	UpdatePasswordHash(ctx context.Context, id, password string) error
 }
 
-// Expiration time of access token
-const accessExp = time.Minute * 15
+// RefreshTokenRepository repository interface for refresh tokens
+//
+//go:generate mockery --name=RefreshTokenRepository --case=underscore --output=./mocks
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeOtherFamilies(ctx context.Context, userID, exceptFamilyID string) error
+	CreateTokenEvent(ctx context.Context, event *model.TokenEvent) error
+}
+
+// SessionRepository repository interface for user sessions
+//
+//go:generate mockery --name=SessionRepository --case=underscore --output=./mocks
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *model.Session) error
+	GetSessionByID(ctx context.Context, id string) (*model.Session, error)
+	GetSessionsByUser(ctx context.Context, userID string) ([]*model.Session, error)
+	TouchSession(ctx context.Context, session *model.Session) error
+	RevokeSession(ctx context.Context, userID, id string) error
+	RevokeOtherSessions(ctx context.Context, userID, exceptID string) error
+}
+
+// SecondFactorRepository repository interface for recovery codes and login challenges
+//
+//go:generate mockery --name=SecondFactorRepository --case=underscore --output=./mocks
+type SecondFactorRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error)
+	DeleteRecoveryCodes(ctx context.Context, userID string) error
+	CreateChallenge(ctx context.Context, challenge *model.LoginChallenge) error
+	AttemptChallenge(ctx context.Context, hash string) (*model.LoginChallenge, error)
+	CompleteChallenge(ctx context.Context, id string) (bool, error)
+	CompleteChallengeWithRecoveryCode(ctx context.Context, id, userID, hash string) (bool, error)
+}
+
+// APIKeyRepository repository interface for api keys
+//
+//go:generate mockery --name=APIKeyRepository --case=underscore --output=./mocks
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *model.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	GetAPIKeysByUser(ctx context.Context, userID string) ([]*model.APIKey, error)
+	TouchAPIKey(ctx context.Context, id string) error
+	RevokeAPIKey(ctx context.Context, userID, id string) error
+	RevokeAPIKeysByUser(ctx context.Context, userID string) error
+}
+
+// ServiceAccountRepository repository interface for service accounts
+//
+//go:generate mockery --name=ServiceAccountRepository --case=underscore --output=./mocks
+type ServiceAccountRepository interface {
+	CreateServiceAccount(ctx context.Context, account *model.ServiceAccount) error
+	GetServiceAccountByClientID(ctx context.Context, clientID string) (*model.ServiceAccount, error)
+	GetServiceAccountByID(ctx context.Context, id string) (*model.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id string) error
+}
+
+// OneTimeTokenRepository repository interface for single use tokens sent to users
+//
+//go:generate mockery --name=OneTimeTokenRepository --case=underscore --output=./mocks
+type OneTimeTokenRepository interface {
+	CreateOneTimeToken(ctx context.Context, token *model.OneTimeToken) error
+	GetOneTimeToken(ctx context.Context, purpose, hash string) (*model.OneTimeToken, error)
+	UseOneTimeToken(ctx context.Context, purpose, hash string) (*model.OneTimeToken, error)
+	RevokeOneTimeTokens(ctx context.Context, userID string) error
+}
+
+// PasswordHistoryRepository repository interface for previous password hashes
+//
+//go:generate mockery --name=PasswordHistoryRepository --case=underscore --output=./mocks
+type PasswordHistoryRepository interface {
+	AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error
+	GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+}
+
+// AuditRepository repository interface for audit log
+//
+//go:generate mockery --name=AuditRepository --case=underscore --output=./mocks
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, event *model.AuditEvent) error
+}
+
+// Notifier delivery of notifications to users
+//
+//go:generate mockery --name=Notifier --case=underscore --output=./mocks
+type Notifier interface {
+	Send(ctx context.Context, notification *model.Notification) error
+}
 
-// Expiration time of refresh token
-const refreshExp = time.Hour * 10
+// Repositories repositories used by user service
+type Repositories struct {
+	Users           UserRepository
+	RefreshTokens   RefreshTokenRepository
+	Sessions        SessionRepository
+	SecondFactors   SecondFactorRepository
+	APIKeys         APIKeyRepository
+	ServiceAccounts ServiceAccountRepository
+	OneTimeTokens   OneTimeTokenRepository
+	Audit           AuditRepository
+	PasswordHistory PasswordHistoryRepository
+}
 
-// Strength of password
-const passwordStrength = 50
+// Config settings of user service
+type Config struct {
+	// TOTPKey secret from which key for totp secrets encryption is derived
+	TOTPKey    string
+	TOTPIssuer string
+	// MagicLinkURL url of login page, token is added as query parameter
+	MagicLinkURL string
+	// PasswordResetURL url of password reset page, token is added as query parameter
+	PasswordResetURL string
+	// Issuer iss claim of issued tokens, it is also the audience of refresh tokens
+	Issuer string
+	// Audiences aud claim of issued access tokens
+	Audiences []string
+	Lifetimes Lifetimes
+	// Argon2 parameters of password hashes, outdated hashes are upgraded on login
+	Argon2 Argon2Params
+	// BreachPolicy reject or warn about passwords found in breached passwords
+	BreachPolicy string
+	// PasswordHistory number of latest passwords which cannot be reused, zero disables history
+	PasswordHistory int
+	// PasswordPolicy rules for new passwords, expired passwords have to be reset before login
+	PasswordPolicy PasswordPolicy
+}
 
 // User user service
 type User struct {
-	rps    UserRepository
-	jwtKey []byte
+	rps             UserRepository
+	tokens          RefreshTokenRepository
+	sessions        SessionRepository
+	secondFactors   SecondFactorRepository
+	apiKeys         APIKeyRepository
+	serviceAccounts ServiceAccountRepository
+	oneTimeTokens   OneTimeTokenRepository
+	audit           AuditRepository
+	history         PasswordHistoryRepository
+	denylist        *Denylist
+	versions        *TokenVersions
+	lockout         *Lockout
+	keys            *KeySet
+	tokenFormat     TokenFormat
+	hasher          PasswordHasher
+	notifier        Notifier
+	breached        BreachedPasswords
+	breachPolicy    string
+	historySize     int
+	policy          PasswordPolicy
+	totpKey         []byte
+	totpIssuer      string
+	magicLinkURL    string
+	resetURL        string
+	issuer          string
+	audiences       []string
+	lifetimes       Lifetimes
 }
 
-// CustomClaims claims with id and role
+// CustomClaims claims with id, role, token type, session, scopes and token version of user,
+// tokens of service accounts carry client id and no session,
+// impersonation tokens carry admin as actor and no session
 type CustomClaims struct {
-	ID   string `json:"id"`
-	Role string `json:"role"`
+	ID        string       `json:"id"`
+	Role      string       `json:"role"`
+	Type      string       `json:"typ"`
+	SessionID string       `json:"sid,omitempty"`
+	ClientID  string       `json:"client_id,omitempty"`
+	Scopes    []string     `json:"scp,omitempty"`
+	Actor     *model.Actor `json:"act,omitempty"`
+	Version   int          `json:"ver,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// NewUserServiceClassic new user service
-func NewUserServiceClassic(rps UserRepository, key string) *User {
-	return &User{rps: rps, jwtKey: []byte(key)}
+// Impersonated token was issued to someone acting as the subject
+func (c *CustomClaims) Impersonated() bool {
+	return c.Actor != nil
+}
+
+// NewUserServiceClassic new user service, tokens are issued in token format and its public keys are published from key set,
+// new passwords are screened against breached passwords unless it is nil
+func NewUserServiceClassic(rps Repositories, denylist *Denylist, versions *TokenVersions, lockout *Lockout, keys *KeySet,
+	tokenFormat TokenFormat, notifier Notifier, breached BreachedPasswords, cfg Config) *User {
+	totpKey := sha256.Sum256([]byte(cfg.TOTPKey))
+	return &User{
+		rps:             rps.Users,
+		tokens:          rps.RefreshTokens,
+		sessions:        rps.Sessions,
+		secondFactors:   rps.SecondFactors,
+		apiKeys:         rps.APIKeys,
+		serviceAccounts: rps.ServiceAccounts,
+		oneTimeTokens:   rps.OneTimeTokens,
+		audit:           rps.Audit,
+		history:         rps.PasswordHistory,
+		denylist:        denylist,
+		versions:        versions,
+		lockout:         lockout,
+		keys:            keys,
+		tokenFormat:     tokenFormat,
+		hasher:          NewArgon2id(cfg.Argon2),
+		notifier:        notifier,
+		breached:        breached,
+		breachPolicy:    cfg.BreachPolicy,
+		historySize:     cfg.PasswordHistory,
+		policy:          cfg.PasswordPolicy,
+		totpKey:         totpKey[:],
+		totpIssuer:      cfg.TOTPIssuer,
+		magicLinkURL:    cfg.MagicLinkURL,
+		resetURL:        cfg.PasswordResetURL,
+		issuer:          cfg.Issuer,
+		audiences:       cfg.Audiences,
+		lifetimes:       cfg.Lifetimes,
+	}
 }
 
 // Signup service signup
-func (u *User) Signup(ctx context.Context, user *model.User) (accessToken, refreshToken string, userResult *model.User, err error) {
+func (u *User) Signup(ctx context.Context, user *model.User, session *model.Session) (accessToken, refreshToken string, userResult *model.User, err error) {
 	userResult = &model.User{}
-	if err = passwordvalidator.Validate(user.Password, passwordStrength); err != nil {
-		return "", "", nil, fmt.Errorf("userService - Signup - Validate: %w", err)
+	if err = u.validatePassword(user.Password, user); err != nil {
+		return "", "", nil, fmt.Errorf("userService - Signup - validatePassword: %w", err)
 	}
-	user.Password, err = hashingPassword(user.Password)
+	user.Password, err = u.hasher.Hash(user.Password)
 	if err != nil {
-		return "", "", nil, err
+		return "", "", nil, fmt.Errorf("userService - Signup - Hash: %w", err)
 	}
 	user.ID = uuid.New().String()
 	userResult.ID = user.ID
@@ -69,8 +261,15 @@ func (u *User) Signup(ctx context.Context, user *model.User) (accessToken, refre
 	if userResult, err = u.rps.CreateUser(ctx, user); err != nil {
 		return "", "", nil, fmt.Errorf("userService - Signup - CreateUser: %w", err)
 	}
+	if err = u.addPasswordHistory(ctx, userResult.ID, userResult.Password); err != nil {
+		return "", "", nil, fmt.Errorf("userService - Signup - addPasswordHistory: %w", err)
+	}
+
+	if err = u.createSession(ctx, userResult, session); err != nil {
+		return "", "", nil, fmt.Errorf("userService - Signup - createSession: %w", err)
+	}
 
-	accessToken, refreshToken, err = u.createJWT(ctx, userResult)
+	accessToken, refreshToken, err = u.createJWT(ctx, userResult, session)
 	if err != nil {
 		return "", "", nil, fmt.Errorf("userService - Signup - createJWT: %w", err)
 	}
@@ -78,43 +277,104 @@ func (u *User) Signup(ctx context.Context, user *model.User) (accessToken, refre
 	return
 }
 
-// Login service login
-//
-//nolint:dupl //just because
-func (u *User) Login(ctx context.Context, login, password string) (accessToken, refreshToken string, err error) {
-	var user *model.User
+// Login service login, every login starts new session, failed attempts are counted per login and client ip
+// and lock them out after thresholds,
+// users with second factor get only challenge token which must be passed to VerifySecondFactor
+func (u *User) Login(ctx context.Context, login, password string, session *model.Session) (accessToken, refreshToken, challengeToken string, err error) {
+	if err = u.lockout.Check(ctx, login, session.IP); err != nil {
+		return "", "", "", fmt.Errorf("userService - Login - Check: %w", err)
+	}
 
+	var user *model.User
 	if user, err = u.rps.GetUserByLogin(ctx, login); err != nil {
-		return "", "", fmt.Errorf("userService - Login - GetUserByLogin: %w", err)
+		logrus.Debugf("userService - Login - GetUserByLogin: %v", err)
+		// hashing takes as long as verification, so unknown logins cannot be told apart by response time
+		_, _ = u.hasher.Hash(password)
+		return "", "", "", u.loginFailed(ctx, login, session.IP)
 	}
 
-	if !checkPasswordHash(user.Password, password) {
-		return "", "", fmt.Errorf("userService - Login - Password invalid: %w", err)
+	var valid bool
+	if valid, err = u.hasher.Verify(user.Password, password); err != nil {
+		return "", "", "", fmt.Errorf("userService - Login - Verify: %w", err)
 	}
+	if !valid {
+		return "", "", "", u.loginFailed(ctx, login, session.IP)
+	}
+	if err = u.lockout.Reset(ctx, login); err != nil {
+		return "", "", "", fmt.Errorf("userService - Login - Reset: %w", err)
+	}
+	if u.policy.Expired(user.PasswordChanged) {
+		return "", "", "", fmt.Errorf("userService - Login: %w", ErrPasswordExpired)
+	}
+	u.rehashPassword(ctx, user, password)
 
-	accessToken, refreshToken, err = u.createJWT(ctx, user)
+	accessToken, refreshToken, challengeToken, err = u.startSession(ctx, user, session)
 	if err != nil {
-		return "", "", fmt.Errorf("userService - Login - createJWT: %w", err)
+		return "", "", "", fmt.Errorf("userService - Login - startSession: %w", err)
 	}
 
 	return
 }
 
-// Refresh service refresh
-//
-//nolint:dupl //just because
-func (u *User) Refresh(ctx context.Context, id, userRefreshToken string) (accessToken, refreshToken string, err error) {
-	var user *model.User
+// loginFailed counting failed login attempt, the same error is returned for unknown login and invalid password
+func (u *User) loginFailed(ctx context.Context, login, ip string) error {
+	if err := u.lockout.Fail(ctx, login, ip); err != nil {
+		return fmt.Errorf("userService - loginFailed - Fail: %w", err)
+	}
+
+	return fmt.Errorf("userService - loginFailed: %w", ErrInvalidCredentials)
+}
 
+// Refresh service refresh of session, every refresh token can be used only once,
+// presenting used token again revokes the whole token family,
+// sessions older than absolute session lifetime of role and expired passwords require new login
+func (u *User) Refresh(ctx context.Context, id, userRefreshToken string, client *model.Session) (accessToken, refreshToken string, err error) {
+	var stored *model.RefreshToken
+	if stored, err = u.tokens.GetRefreshTokenByHash(ctx, hashToken(userRefreshToken)); err != nil {
+		return "", "", fmt.Errorf("userService - Refresh - GetRefreshTokenByHash: %w", err)
+	}
+	if stored.UserID != id || stored.Revoked || time.Now().After(stored.Expires) {
+		return "", "", fmt.Errorf("userService - Refresh - Token invalid")
+	}
+
+	var fresh bool
+	if !stored.Used {
+		if fresh, err = u.tokens.UseRefreshToken(ctx, stored.ID); err != nil {
+			return "", "", fmt.Errorf("userService - Refresh - UseRefreshToken: %w", err)
+		}
+	}
+	if !fresh {
+		if err = u.revokeFamily(ctx, stored, model.TokenEventReuseDetected); err != nil {
+			return "", "", fmt.Errorf("userService - Refresh - revokeFamily: %w", err)
+		}
+		return "", "", fmt.Errorf("userService - Refresh - Token reuse detected")
+	}
+
+	var session *model.Session
+	if session, err = u.sessions.GetSessionByID(ctx, stored.FamilyID); err != nil {
+		return "", "", fmt.Errorf("userService - Refresh - GetSessionByID: %w", err)
+	}
+	if session.Revoked {
+		return "", "", fmt.Errorf("userService - Refresh - Session revoked")
+	}
+
+	var user *model.User
 	if user, err = u.rps.GetUserByID(ctx, id); err != nil {
 		return "", "", fmt.Errorf("userService - Refresh - GetUserByID: %w", err)
 	}
+	if u.lifetimes.ForRole(user.Role).sessionExpired(session, time.Now()) {
+		return "", "", fmt.Errorf("userService - Refresh - Session expired")
+	}
+	if u.policy.Expired(user.PasswordChanged) {
+		return "", "", fmt.Errorf("userService - Refresh: %w", ErrPasswordExpired)
+	}
 
-	if user.Token != userRefreshToken {
-		return "", "", fmt.Errorf("userService - Refresh - Token invalid: %w", err)
+	session.UserAgent, session.IP = client.UserAgent, client.IP
+	if err = u.sessions.TouchSession(ctx, session); err != nil {
+		return "", "", fmt.Errorf("userService - Refresh - TouchSession: %w", err)
 	}
 
-	accessToken, refreshToken, err = u.createJWT(ctx, user)
+	accessToken, refreshToken, err = u.createJWT(ctx, user, session)
 	if err != nil {
 		return "", "", fmt.Errorf("userService - Refresh - createJWT: %w", err)
 	}
@@ -158,49 +418,104 @@ func (u *User) GetByID(ctx context.Context, id string) (user *model.User, err er
 	return
 }
 
-func (u *User) createJWT(ctx context.Context, user *model.User) (accessTokenStr, refreshTokenStr string, err error) {
+// JWKS public keys for token verification
+func (u *User) JWKS(ctx context.Context) *model.JWKS {
+	return u.keys.JWKS()
+}
+
+// createJWT creating token pair for session, session id is used as refresh token family,
+// lifetimes are taken from role of user and limited by absolute session lifetime
+func (u *User) createJWT(ctx context.Context, user *model.User, session *model.Session) (accessTokenStr, refreshTokenStr string, err error) {
+	now := time.Now()
+	lifetime := u.lifetimes.ForRole(user.Role)
+	accessExpires := lifetime.capToSession(session, now.Add(lifetime.Access))
+
 	accessClaims := &CustomClaims{
-		user.ID,
-		user.Role,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessExp)),
-		},
-	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenStr, err = accessToken.SignedString(u.jwtKey)
+		ID:               user.ID,
+		Role:             user.Role,
+		Type:             TokenTypeAccess,
+		SessionID:        session.ID,
+		Version:          user.TokenVersion,
+		Scopes:           model.RoleScopes(user.Role),
+		RegisteredClaims: u.registeredClaims(user.ID, uuid.New().String(), u.audiences, accessExpires),
+	}
+	accessTokenStr, err = u.signToken(accessClaims)
 	if err != nil {
-		return "", "", fmt.Errorf("userService - createJWT - S	ignedString: %w", err)
+		return "", "", fmt.Errorf("userService - createJWT - signToken: %w", err)
 	}
 
+	stored := &model.RefreshToken{
+		ID:       uuid.New().String(),
+		FamilyID: session.ID,
+		UserID:   user.ID,
+		Expires:  lifetime.refreshExpiration(session, now),
+	}
 	refreshClaims := &CustomClaims{
-		user.ID,
-		user.Role,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshExp)),
-		},
-	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenStr, err = refreshToken.SignedString(u.jwtKey)
+		ID:               user.ID,
+		Role:             user.Role,
+		Type:             TokenTypeRefresh,
+		SessionID:        session.ID,
+		Version:          user.TokenVersion,
+		RegisteredClaims: u.registeredClaims(user.ID, stored.ID, []string{u.issuer}, stored.Expires),
+	}
+	refreshTokenStr, err = u.signToken(refreshClaims)
 	if err != nil {
-		return "", "", fmt.Errorf("userService - createJWT - SignedString: %w", err)
+		return "", "", fmt.Errorf("userService - createJWT - signToken: %w", err)
 	}
 
-	err = u.rps.RefreshUser(ctx, user.ID, refreshTokenStr)
+	stored.TokenHash = hashToken(refreshTokenStr)
+	err = u.tokens.CreateRefreshToken(ctx, stored)
 	if err != nil {
-		return "", "", fmt.Errorf("userService - createJWT - RefreshUser: %w", err)
+		return "", "", fmt.Errorf("userService - createJWT - CreateRefreshToken: %w", err)
 	}
 	return accessTokenStr, refreshTokenStr, err
 }
 
-func hashingPassword(password string) (string, error) {
-	hashedBytesPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// registeredClaims registered claims of token issued now
+func (u *User) registeredClaims(subject, id string, audience []string, expires time.Time) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        id,
+		Subject:   subject,
+		Issuer:    u.issuer,
+		Audience:  audience,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expires),
+	}
+}
+
+// revokeFamily revoking all tokens of family and recording the reason
+func (u *User) revokeFamily(ctx context.Context, token *model.RefreshToken, reason string) error {
+	if err := u.tokens.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return fmt.Errorf("userService - revokeFamily - RevokeFamily: %w", err)
+	}
+
+	event := &model.TokenEvent{
+		ID:       uuid.New().String(),
+		UserID:   token.UserID,
+		FamilyID: token.FamilyID,
+		Type:     reason,
+	}
+	if err := u.tokens.CreateTokenEvent(ctx, event); err != nil {
+		return fmt.Errorf("userService - revokeFamily - CreateTokenEvent: %w", err)
+	}
+
+	return nil
+}
+
+// signToken signing claims in configured token format
+func (u *User) signToken(claims *CustomClaims) (string, error) {
+	token, err := u.tokenFormat.Issue(claims)
 	if err != nil {
-		return "", fmt.Errorf("user - hashingPassword - GenerateFromPassword: %w", err)
+		return "", fmt.Errorf("userService - signToken - Issue: %w", err)
 	}
-	return string(hashedBytesPassword), nil
+
+	return token, nil
 }
 
-func checkPasswordHash(hash, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// hashToken hash of token for storing, tokens have enough entropy for sha256
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
@@ -5,9 +5,15 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 
+	"github.com/OVantsevich/User-Service/internal/breach"
 	"github.com/OVantsevich/User-Service/internal/config"
 	"github.com/OVantsevich/User-Service/internal/handler"
+	"github.com/OVantsevich/User-Service/internal/middleware"
+	"github.com/OVantsevich/User-Service/internal/model"
+	"github.com/OVantsevich/User-Service/internal/notifier"
+	"github.com/OVantsevich/User-Service/internal/ratelimit"
 	"github.com/OVantsevich/User-Service/internal/repository"
 	"github.com/OVantsevich/User-Service/internal/service"
 	pr "github.com/OVantsevich/User-Service/proto"
@@ -28,20 +34,114 @@ func main() {
 		logrus.Fatalf("error while listening port: %v", err)
 	}
 
-	var repos service.UserRepository
-	repos, err = dbConnection(cfg)
+	pool, err := dbConnection(cfg)
 	if err != nil {
 		logrus.Fatal(err)
 	}
-	defer closePool(repos)
+	defer pool.Close()
 
-	userService := service.NewUserServiceClassic(repos, cfg.JwtKey)
+	lifetimes := newLifetimes(cfg)
+	keys, err := service.NewKeySet(cfg.JwtAlgorithm, cfg.JwtKeysDir, cfg.JwksFile, cfg.JwtRotationPeriod, lifetimes.Longest(),
+		cfg.JwtKeyRotator)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	tokenFormat, err := service.NewTokenFormat(cfg.TokenFormat, keys)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	denylist := service.NewDenylist(repository.NewDenylist(pool))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go denylist.Cleanup(ctx, cfg.DenylistCleanup)
+	go keys.Watch(ctx, cfg.JwtKeysReload)
+
+	lockout := service.NewLockout(repository.NewLoginAttempt(pool), service.LockoutPolicy{
+		LoginThreshold: cfg.LoginThreshold,
+		IPThreshold:    cfg.IPThreshold,
+		BaseLock:       cfg.LockoutBase,
+		MaxLock:        cfg.LockoutMax,
+		Window:         cfg.LockoutWindow,
+	})
+	go lockout.Cleanup(ctx, cfg.LockoutCleanup)
+
+	rateLimits, err := newRateLimitRepository(cfg, pool)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	limiter := service.NewRateLimiter(rateLimits)
+	go limiter.Cleanup(ctx, cfg.RateLimitCleanup)
+	limits, defaultLimit, err := newRateLimits(cfg)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	proxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	if err = service.ValidateBreachPolicy(cfg.BreachPolicy); err != nil {
+		logrus.Fatal(err)
+	}
+	argon2 := service.Argon2Params{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+		SaltLength:  cfg.Argon2SaltLength,
+		KeyLength:   cfg.Argon2KeyLength,
+	}
+	if err = service.ValidateArgon2Params(argon2); err != nil {
+		logrus.Fatal(err)
+	}
+	breached, err := newBreachedPasswords(cfg)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	users := repository.NewUser(pool)
+	versions := service.NewTokenVersions(users, cfg.TokenVersionTTL)
 
-	//ns := grpc.NewServer(middleware.JwtAuth(func(token *jwt.Token) (interface{}, error) {
-	//	return []byte(cfg.JwtKey), nil
-	//}))
-	ns := grpc.NewServer()
-	server := handler.NewUserHandlerClassic(userService, cfg.JwtKey)
+	repos := service.Repositories{
+		Users:           users,
+		RefreshTokens:   repository.NewRefreshToken(pool),
+		Sessions:        repository.NewSession(pool),
+		SecondFactors:   repository.NewSecondFactor(pool),
+		APIKeys:         repository.NewAPIKey(pool),
+		ServiceAccounts: repository.NewServiceAccount(pool),
+		OneTimeTokens:   repository.NewOneTimeToken(pool),
+		Audit:           repository.NewAudit(pool),
+		PasswordHistory: repository.NewPasswordHistory(pool),
+	}
+	userService := service.NewUserServiceClassic(repos, denylist, versions, lockout, keys, tokenFormat, newNotifier(cfg), breached, service.Config{
+		TOTPKey:          cfg.TotpKey,
+		TOTPIssuer:       cfg.TotpIssuer,
+		MagicLinkURL:     cfg.MagicLinkURL,
+		PasswordResetURL: cfg.PasswordResetURL,
+		BreachPolicy:     cfg.BreachPolicy,
+		PasswordHistory:  cfg.PasswordHistory,
+		PasswordPolicy:   service.PasswordPolicy(cfg.Password),
+		Issuer:           cfg.JwtIssuer,
+		Audiences:        append([]string{cfg.JwtAudience}, cfg.JwtAudiences...),
+		Lifetimes:        lifetimes,
+		Argon2:           argon2,
+	})
+
+	var options []grpc.ServerOption
+	options = append(options, middleware.ClientIP(proxies)...)
+	if cfg.RateLimitIP != "" {
+		ipLimit, err := middleware.ParseLimit(cfg.RateLimitIP)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		options = append(options, middleware.IPRateLimit(limiter, ipLimit, cfg.RateLimitFailOpen)...)
+	}
+	options = append(options, middleware.JwtAuth(tokenFormat, cfg.JwtIssuer, cfg.JwtAudience, denylist, versions, userService, userService,
+		userService, handler.Policies())...)
+	options = append(options, middleware.RateLimit(limiter, limits, defaultLimit, cfg.RateLimitFailOpen)...)
+	ns := grpc.NewServer(options...)
+	server := handler.NewUserHandlerClassic(userService)
 	pr.RegisterUserServiceServer(ns, server)
 
 	if err = ns.Serve(listen); err != nil {
@@ -49,7 +149,7 @@ func main() {
 	}
 }
 
-func dbConnection(cfg *config.MainConfig) (service.UserRepository, error) {
+func dbConnection(cfg *config.MainConfig) (*pgxpool.Pool, error) {
 	pgURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", cfg.PostgresUser, cfg.PostgresPassword,
 		cfg.PostgresHost, cfg.PostgresPort, cfg.PostgresDB)
 
@@ -60,12 +160,80 @@ func dbConnection(cfg *config.MainConfig) (service.UserRepository, error) {
 	if err = pool.Ping(context.Background()); err != nil {
 		return nil, fmt.Errorf("database not responding: %v", err)
 	}
-	return repository.NewUser(pool), nil
+	return pool, nil
 }
 
-func closePool(r interface{}) {
-	p := r.(repository.User)
-	if p.Pool != nil {
-		p.Pool.Close()
+func newNotifier(cfg *config.MainConfig) service.Notifier {
+	if cfg.Notifier == "smtp" {
+		return notifier.NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+	return notifier.NewFile(cfg.NotifierFile)
+}
+
+func newLifetimes(cfg *config.MainConfig) service.Lifetimes {
+	lifetime := func(l config.TokenLifetimes) service.Lifetime {
+		return service.Lifetime{
+			Access:     l.AccessTokenTTL,
+			Refresh:    l.RefreshTokenTTL,
+			RememberMe: l.RememberMeTTL,
+			SessionMax: l.SessionMaxTTL,
+		}
+	}
+
+	return service.Lifetimes{
+		Default: lifetime(config.TokenLifetimes{
+			AccessTokenTTL:  cfg.AccessTokenTTL,
+			RefreshTokenTTL: cfg.RefreshTokenTTL,
+			RememberMeTTL:   cfg.RememberMeTTL,
+			SessionMaxTTL:   cfg.SessionMaxTTL,
+		}),
+		Roles: map[string]service.Lifetime{
+			model.RoleAdmin:   lifetime(cfg.AdminTokens),
+			model.RoleUser:    lifetime(cfg.UserTokens),
+			model.RoleService: lifetime(cfg.ServiceTokens),
+		},
+	}
+}
+
+func newBreachedPasswords(cfg *config.MainConfig) (service.BreachedPasswords, error) {
+	if cfg.BreachedPasswords == "" {
+		return nil, nil
+	}
+	return breach.NewRange(cfg.BreachedPasswords)
+}
+
+func newRateLimitRepository(cfg *config.MainConfig, pool *pgxpool.Pool) (service.RateLimitRepository, error) {
+	switch cfg.RateLimitBackend {
+	case "postgres":
+		return repository.NewRateLimit(pool), nil
+	case "memory":
+		return ratelimit.NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("rate limit backend %q is not memory or postgres", cfg.RateLimitBackend)
+	}
+}
+
+// newRateLimits per method limits written as Method=count/period and default limit, nil if it is not set
+func newRateLimits(cfg *config.MainConfig) (middleware.Limits, *middleware.Limit, error) {
+	limits := middleware.Limits{}
+	for _, spec := range cfg.RateLimits {
+		name, value, found := strings.Cut(strings.TrimSpace(spec), "=")
+		if !found {
+			return nil, nil, fmt.Errorf("rate limit %q is not Method=count/period", spec)
+		}
+		limit, err := middleware.ParseLimit(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		limits["/"+pr.UserService_ServiceDesc.ServiceName+"/"+name] = limit
+	}
+
+	if cfg.RateLimitDefault == "" {
+		return limits, nil, nil
+	}
+	defaultLimit, err := middleware.ParseLimit(cfg.RateLimitDefault)
+	if err != nil {
+		return nil, nil, err
 	}
+	return limits, &defaultLimit, nil
 }
@@ -0,0 +1,5 @@
+alter table sessions
+    add column if not exists remember boolean not null default false;
+
+alter table login_challenges
+    add column if not exists remember boolean not null default false;
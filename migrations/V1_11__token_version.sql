@@ -0,0 +1,2 @@
+alter table users
+    add column if not exists token_version integer not null default 0;
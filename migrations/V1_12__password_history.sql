@@ -0,0 +1,15 @@
+create table if not exists password_history
+(
+    id            varchar(100)
+        constraint PasswordHistory_pk
+            primary key,
+    user_id       varchar(100)                               not null,
+    password_hash varchar(200)                               not null,
+    created       timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table password_history
+    owner to postgres;
+
+create index if not exists password_history_user_id_index
+    on password_history (user_id, created desc);
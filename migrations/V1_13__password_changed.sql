@@ -0,0 +1,2 @@
+alter table users
+    add column if not exists password_changed timestamp(6) not null default CURRENT_TIMESTAMP(6);
@@ -0,0 +1,13 @@
+create table if not exists login_attempts
+(
+    kind         varchar(20)                                not null,
+    key          varchar(200)                               not null,
+    failures     integer                                    not null,
+    locked_until timestamp(6),
+    last_failure timestamp(6) default CURRENT_TIMESTAMP(6) not null,
+    constraint LoginAttempts_pk
+        primary key (kind, key)
+);
+
+alter table login_attempts
+    owner to postgres;
@@ -0,0 +1,13 @@
+create table if not exists rate_limits
+(
+    key     varchar(300)
+        constraint RateLimits_pk
+            primary key,
+    tokens  double precision not null,
+    rate    double precision not null,
+    burst   integer          not null,
+    updated timestamp(6)     not null
+);
+
+alter table rate_limits
+    owner to postgres;
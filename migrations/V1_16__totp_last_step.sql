@@ -0,0 +1,2 @@
+alter table users
+    add column if not exists totp_last_step bigint;
@@ -0,0 +1,43 @@
+create table if not exists refresh_tokens
+(
+    id         varchar(100)
+        constraint RefreshToken_pk
+            primary key,
+    family_id  varchar(100)                               not null,
+    user_id    varchar(100)                               not null,
+    token_hash varchar(200)                               not null,
+    used       boolean                                    not null default false,
+    revoked    boolean                                    not null default false,
+    expires    timestamp(6)                               not null,
+    created    timestamp(6) default CURRENT_TIMESTAMP(6) not null,
+    updated    timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table refresh_tokens
+    owner to postgres;
+
+create unique index if not exists refresh_tokens_token_hash_uindex
+    on refresh_tokens (token_hash);
+
+create index if not exists refresh_tokens_family_id_index
+    on refresh_tokens (family_id);
+
+create table if not exists token_events
+(
+    id        varchar(100)
+        constraint TokenEvent_pk
+            primary key,
+    user_id   varchar(100)                               not null,
+    family_id varchar(100)                               not null,
+    "type"    varchar(50)                                not null,
+    created   timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table token_events
+    owner to postgres;
+
+create index if not exists token_events_user_id_index
+    on token_events (user_id);
+
+alter table users
+    drop column if exists token;
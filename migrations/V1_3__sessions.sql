@@ -0,0 +1,19 @@
+create table if not exists sessions
+(
+    id          varchar(100)
+        constraint Session_pk
+            primary key,
+    user_id     varchar(100)                               not null,
+    device_name varchar(100)                               not null default '',
+    user_agent  varchar(300)                               not null default '',
+    ip          varchar(50)                                not null default '',
+    revoked     boolean                                    not null default false,
+    created     timestamp(6) default CURRENT_TIMESTAMP(6) not null,
+    last_used   timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table sessions
+    owner to postgres;
+
+create index if not exists sessions_user_id_index
+    on sessions (user_id);
@@ -0,0 +1,14 @@
+create table if not exists token_denylist
+(
+    jti     varchar(100)
+        constraint TokenDenylist_pk
+            primary key,
+    expires timestamp(6)                               not null,
+    created timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table token_denylist
+    owner to postgres;
+
+create index if not exists token_denylist_expires_index
+    on token_denylist (expires);
@@ -0,0 +1,40 @@
+alter table users
+    add column if not exists totp_secret varchar(200) not null default '',
+    add column if not exists totp_enabled boolean not null default false;
+
+create table if not exists recovery_codes
+(
+    id        varchar(100)
+        constraint RecoveryCode_pk
+            primary key,
+    user_id   varchar(100)                               not null,
+    code_hash varchar(200)                               not null,
+    used      boolean                                    not null default false,
+    created   timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table recovery_codes
+    owner to postgres;
+
+create index if not exists recovery_codes_user_id_index
+    on recovery_codes (user_id);
+
+create table if not exists login_challenges
+(
+    id          varchar(100)
+        constraint LoginChallenge_pk
+            primary key,
+    user_id     varchar(100)                               not null,
+    token_hash  varchar(200)                               not null,
+    device_name varchar(100)                               not null default '',
+    attempts    integer                                    not null default 0,
+    used        boolean                                    not null default false,
+    expires     timestamp(6)                               not null,
+    created     timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table login_challenges
+    owner to postgres;
+
+create unique index if not exists login_challenges_token_hash_uindex
+    on login_challenges (token_hash);
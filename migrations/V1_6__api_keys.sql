@@ -0,0 +1,24 @@
+create table if not exists api_keys
+(
+    id        varchar(100)
+        constraint ApiKey_pk
+            primary key,
+    user_id   varchar(100)                               not null,
+    "name"    varchar(100)                               not null,
+    prefix    varchar(50)                                not null,
+    key_hash  varchar(200)                               not null,
+    scopes    text[]                                     not null default '{}',
+    expires   timestamp(6),
+    revoked   boolean                                    not null default false,
+    last_used timestamp(6),
+    created   timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table api_keys
+    owner to postgres;
+
+create unique index if not exists api_keys_key_hash_uindex
+    on api_keys (key_hash);
+
+create index if not exists api_keys_user_id_index
+    on api_keys (user_id);
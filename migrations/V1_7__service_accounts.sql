@@ -0,0 +1,26 @@
+insert into roles (id, "name")
+values (gen_random_uuid(), 'service')
+on conflict do nothing;
+
+create table if not exists service_accounts
+(
+    id          varchar(100)
+        constraint ServiceAccount_pk
+            primary key,
+    "name"      varchar(100)                               not null,
+    client_id   varchar(100)                               not null,
+    secret_hash varchar(200)                               not null,
+    "role"      varchar(50)                                not null default 'service'
+        constraint ServiceAccount_role_fk
+            references roles ("name"),
+    owner_id    varchar(100)                               not null,
+    deleted     boolean                                    not null default false,
+    created     timestamp(6) default CURRENT_TIMESTAMP(6) not null,
+    updated     timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table service_accounts
+    owner to postgres;
+
+create unique index if not exists service_accounts_client_id_uindex
+    on service_accounts (client_id);
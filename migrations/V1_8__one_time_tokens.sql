@@ -0,0 +1,18 @@
+create table if not exists one_time_tokens
+(
+    id         varchar(100)
+        constraint OneTimeToken_pk
+            primary key,
+    user_id    varchar(100)                               not null,
+    purpose    varchar(50)                                not null,
+    token_hash varchar(200)                               not null,
+    used       boolean                                    not null default false,
+    expires    timestamp(6)                               not null,
+    created    timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table one_time_tokens
+    owner to postgres;
+
+create unique index if not exists one_time_tokens_token_hash_uindex
+    on one_time_tokens (token_hash);
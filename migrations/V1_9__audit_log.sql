@@ -0,0 +1,22 @@
+create table if not exists audit_log
+(
+    id         varchar(100)
+        constraint AuditEvent_pk
+            primary key,
+    actor_id   varchar(100)                               not null,
+    subject_id varchar(100)                               not null,
+    action     varchar(50)                                not null,
+    reason     varchar(500)                               not null default '',
+    token_id   varchar(100)                               not null default '',
+    expires    timestamp(6),
+    created    timestamp(6) default CURRENT_TIMESTAMP(6) not null
+);
+
+alter table audit_log
+    owner to postgres;
+
+create index if not exists audit_log_actor_id_index
+    on audit_log (actor_id);
+
+create index if not exists audit_log_subject_id_index
+    on audit_log (subject_id);